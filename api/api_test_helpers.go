@@ -9,6 +9,13 @@ import (
 )
 
 func createTestDeck(router *gin.Engine, params string) uuid.UUID {
+	deckID, _ := createTestDeckWithToken(router, params)
+
+	return deckID
+}
+
+// createTestDeckWithToken creates a deck, and returns its ID and owner token.
+func createTestDeckWithToken(router *gin.Engine, params string) (uuid.UUID, string) {
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(http.MethodPost, "/deck/new"+params, nil)
 	router.ServeHTTP(w, req)
@@ -17,19 +24,17 @@ func createTestDeck(router *gin.Engine, params string) uuid.UUID {
 	// This should never fail.
 	_ = json.Unmarshal(w.Body.Bytes(), &createResponse)
 
-	deckID := createResponse.DeckID
-
-	return deckID
+	return createResponse.DeckID, createResponse.OwnerToken
 }
 
 // TODO: Some way to make this run before each test?
 //
 // Update: TestMain does not work because we need access to the router created by setup().
-func setup() *gin.Engine {
+func setup(opts ...Option) *gin.Engine {
 	// Lightweight mode for testing.
 	gin.SetMode(gin.TestMode)
 
-	server := NewServer()
+	server := NewServer(opts...)
 
 	return server.router
 }
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"crypto/subtle"
+	"deck-of-cards/deck"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// ownerToken returns the owner token sent with the request, as "Authorization: Bearer <token>".
+// It returns an empty string if there is none.
+//
+// Only the owner of a deck can change its order or take cards back (shuffle, return, insert, deal, close, undo and
+// redo), as those wipe the hands, or discard the proof of a deck shuffled with a commitment. Drawing from the deck and
+// managing its piles stay open to the players: they take the next cards in the order the owner set, without revealing
+// the cards left.
+func ownerToken(c *gin.Context) string {
+	token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// isOwner checks whether the request was sent by the owner of the deck (e.g. the dealer), who received the deck's
+// owner token on creation.
+func isOwner(c *gin.Context, d *deck.Deck) bool {
+	return d.IsOwner(ownerToken(c))
+}
+
+// Actors recorded in the history of a deck (see deck.Deck.SetActor), depending on who sent the request.
+const (
+	actorOwner     = "owner"
+	actorAdmin     = "admin"
+	actorAnonymous = "anonymous"
+)
+
+// actor returns who sent the request, to be recorded in the history of the deck: its owner, the administrator of the
+// server, or anybody else.
+func (server *Server) actor(c *gin.Context, d *deck.Deck) string {
+	switch {
+	case isOwner(c, d):
+		return actorOwner
+	case server.isAdmin(c):
+		return actorAdmin
+	default:
+		return actorAnonymous
+	}
+}
+
+// isAdmin checks whether the request was sent by the administrator of the server (see WithAdminToken).
+func (server *Server) isAdmin(c *gin.Context) bool {
+	token := ownerToken(c)
+	if server.adminToken == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(server.adminToken)) == 1
+}
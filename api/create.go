@@ -2,59 +2,249 @@ package api
 
 import (
 	"deck-of-cards/deck"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // createDeckHandler is a Gin route handler for creating a new deck of cards.
+// The deck is described by a JSON body (see CreateDeckRequest) or, when there is no body, by the equivalent query
+// parameters, described below. Booleans which can not be parsed (e.g. "shuffled=yes") are rejected with 422
+// Unprocessable Entity.
 // It accepts optional query parameters "cards" and "shuffled" to create a custom deck and shuffle it, respectively.
+// The optional "deck_count" query parameter creates a shoe made of that many standard decks. Combined with "cards",
+// it allows each card to be repeated up to "deck_count" times.
+// The optional "jokers_enabled" query parameter adds two Jokers per standard deck to a full deck. It is ignored
+// when "cards" is provided, as Jokers can be listed there (X1, X2).
+// The optional "seed" query parameter (an integer) shuffles the deck in a reproducible way: decks created with the
+// same cards and the same seed are always in the same order. Providing a seed implies "shuffled=true".
+// Without a seed, decks are shuffled with a cryptographically secure source of randomness, and a commitment to the
+// shuffled order is returned so players can later verify the order was fixed at creation (see proofHandler).
+// The optional "client_seed" query parameter is mixed into that shuffle. It implies "shuffled=true", and can not be
+// used together with "seed".
+// The optional "owner" query parameter names the owner of the deck (e.g. a player or a table), so the deck can be
+// found again with listDecksHandler.
+// The optional "auto_reshuffle" query parameter makes the deck refill itself with its "discard" pile, shuffled, when
+// more cards are drawn than remaining.
+// The optional "metadata[<key>]" query parameters attach data to the deck (e.g. metadata[game]=poker).
 //
 // Example query parameters for creating a partial deck and shuffling it:
 // /decks?cards=AS,KD,QH,2C,3S&shuffled=true
 //
-// The deck information is returned as JSON.
+// Example query parameters for creating a shuffled 6-deck shoe:
+// /decks?deck_count=6&shuffled=true
+//
+// Example body for creating the same shoe:
+// {"deck_count": 6, "shuffled": true}
+//
+// The deck information is returned as JSON, together with the owner token of the deck. If the request is sent with
+// an owner token (see ownerToken), the new deck is owned by that token; otherwise a new owner token is issued.
 func (server *Server) createDeckHandler(c *gin.Context) {
-	queryCards, exists := c.GetQuery("cards")
-	var createdDeck deck.Deck
+	var request CreateDeckRequest
 	var err error
-	if exists {
-		cardCodes := strings.Split(queryCards, ",")
-		createdDeck, err = deck.NewPartialDeck(cardCodes)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-	} else {
-		createdDeck = deck.NewStandardDeck()
+	if c.ContentType() == binding.MIMEJSON {
+		err = c.ShouldBindJSON(&request)
+	}
+	// Some clients always send a JSON content type, even without a body.
+	if c.ContentType() != binding.MIMEJSON || errors.Is(err, io.EOF) {
+		request, err = createDeckRequestFromQuery(c)
+	}
+	if err != nil {
+		respondWithError(c, invalidRequest(request, err))
+		return
+	}
+	if request.Seed != nil && request.ClientSeed != nil {
+		respondWithError(c, invalidParameter("client_seed", "seed and client_seed parameters can not be used together"))
+		return
+	}
+
+	var opts []deck.Option
+	if request.Jokers {
+		opts = append(opts, deck.WithJokers())
 	}
 
-	// TODO: Some better, strongly typed way of doing this?
-	shuffledStr := c.DefaultQuery("shuffled", "false")
+	var createdDeck deck.Deck
+	switch {
+	case request.Cards != nil && request.DeckCount != nil:
+		createdDeck, err = deck.NewPartialShoe(request.Cards, *request.DeckCount)
+	case request.Cards != nil:
+		createdDeck, err = deck.NewPartialDeck(request.Cards)
+	case request.DeckCount != nil:
+		createdDeck, err = deck.NewShoe(*request.DeckCount, opts...)
+	default:
+		createdDeck, err = deck.NewStandardDeck(opts...), nil
+	}
+	if err != nil {
+		respondWithError(c, rejected(err))
+		return
+	}
+	// The creator of the deck receives its owner token. The deck is not stored yet, so its creation, recorded before
+	// anyone could be named, can still be attributed.
+	createdDeck.History[0].Actor = actorOwner
+	createdDeck.SetActor(actorOwner)
+
 	shuffled := false
-	if shuffledStr == "true" {
+	if request.Seed != nil {
 		shuffled = true
+		// Later shuffles of the deck (e.g. reshuffling for a new round) are reproducible too.
+		createdDeck.SetShuffler(deck.NewSeededShuffler(*request.Seed))
 		createdDeck.Shuffle()
+	} else if request.Shuffled || request.ClientSeed != nil {
+		shuffled = true
+		var clientSeed string
+		if request.ClientSeed != nil {
+			clientSeed = *request.ClientSeed
+		}
+		err = createdDeck.CommitShuffle(clientSeed)
+		if err != nil {
+			respondWithError(c, err)
+			return
+		}
+	}
+
+	createdDeck.AutoReshuffle = request.AutoReshuffle
+	createdDeck.Owner = request.Owner
+	createdDeck.Metadata = request.Metadata
+
+	// A client owning other decks can own the new deck with the same token, so it can list all of its decks.
+	token := ownerToken(c)
+	if token != "" {
+		createdDeck.SetOwnerToken(token)
+	} else if token, err = createdDeck.IssueOwnerToken(); err != nil {
+		respondWithError(c, err)
+		return
 	}
 
 	err = server.store.Add(&createdDeck)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, "")
+		respondWithError(c, err)
 		return
 	}
 
 	jsonResponse := CreateDeckResponse{
-		DeckID:    createdDeck.ID,
-		Shuffled:  shuffled,
-		Remaining: createdDeck.Remaining,
+		DeckID:        createdDeck.ID,
+		Shuffled:      shuffled,
+		Remaining:     createdDeck.Remaining,
+		DeckCount:     createdDeck.DeckCount,
+		Seed:          request.Seed,
+		AutoReshuffle: createdDeck.AutoReshuffle,
+		OwnerToken:    token,
+		Owner:         createdDeck.Owner,
+		Metadata:      createdDeck.Metadata,
+		ExpiresAt:     expiresAt(&createdDeck),
+	}
+	if createdDeck.Proof != nil {
+		jsonResponse.Commitment = createdDeck.Proof.Commitment
 	}
 	c.JSON(http.StatusOK, jsonResponse)
 }
 
+// CreateDeckRequest is a struct that represents the JSON body for the createDeckHandler. Every field is optional: an
+// empty object creates a standard, unshuffled deck. Without a body, the query parameters are used instead.
+type CreateDeckRequest struct {
+	// Cards are the codes of the cards of a partial deck (e.g. ["AS", "KD"]). By default, the deck is full.
+	Cards []string `json:"cards"`
+	// Shuffled shuffles the deck with a cryptographically secure source of randomness, and a commitment.
+	Shuffled bool `json:"shuffled"`
+	// Seed shuffles the deck in a reproducible way. It implies Shuffled.
+	Seed *int64 `json:"seed"`
+	// ClientSeed is mixed into the shuffle. It implies Shuffled, and can not be used together with Seed.
+	ClientSeed *string `json:"client_seed"`
+	// DeckCount creates a shoe made of that many standard decks.
+	DeckCount *int `json:"deck_count"`
+	// Jokers adds two Jokers per standard deck to a full deck.
+	Jokers bool `json:"jokers"`
+	// AutoReshuffle makes the deck refill itself with its discard pile when it runs out of cards.
+	AutoReshuffle bool `json:"auto_reshuffle"`
+	// Owner names the owner of the deck.
+	Owner string `json:"owner" binding:"max=64"`
+	// Metadata is attached to the deck, and returned when it is opened.
+	Metadata map[string]string `json:"metadata" binding:"max=16,dive,keys,min=1,max=64,endkeys,max=256"`
+}
+
+// createDeckRequestFromQuery reads the CreateDeckRequest from the query parameters, which createDeckHandler accepted
+// before JSON bodies, and validates it like a JSON body.
+func createDeckRequestFromQuery(c *gin.Context) (CreateDeckRequest, error) {
+	var request CreateDeckRequest
+	var err error
+
+	if cards, exists := c.GetQuery("cards"); exists {
+		request.Cards = strings.Split(cards, ",")
+	}
+	if request.Shuffled, err = boolQuery(c, "shuffled"); err != nil {
+		return request, err
+	}
+	if request.Jokers, err = boolQuery(c, "jokers_enabled"); err != nil {
+		return request, err
+	}
+	if request.AutoReshuffle, err = boolQuery(c, "auto_reshuffle"); err != nil {
+		return request, err
+	}
+	if deckCountStr, isShoe := c.GetQuery("deck_count"); isShoe {
+		deckCount, err := strconv.Atoi(deckCountStr)
+		if err != nil {
+			return request, invalidParameter("deck_count", "deck_count parameter must be a positive integer")
+		}
+		request.DeckCount = &deckCount
+	}
+	if seedStr, seeded := c.GetQuery("seed"); seeded {
+		seed, err := strconv.ParseInt(seedStr, 10, 64)
+		if err != nil {
+			return request, invalidParameter("seed", "seed parameter must be an integer")
+		}
+		request.Seed = &seed
+	}
+	if clientSeed, hasClientSeed := c.GetQuery("client_seed"); hasClientSeed {
+		request.ClientSeed = &clientSeed
+	}
+	request.Owner = c.Query("owner")
+	if metadata := c.QueryMap("metadata"); len(metadata) > 0 {
+		request.Metadata = metadata
+	}
+
+	return request, binding.Validator.ValidateStruct(&request)
+}
+
+// boolQuery parses the optional boolean query parameter. It is false if it is missing.
+func boolQuery(c *gin.Context, param string) (bool, error) {
+	valueStr, exists := c.GetQuery(param)
+	if !exists {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return false, unprocessableParameter(param, fmt.Sprintf("%s parameter must be a boolean", param))
+	}
+	return value, nil
+}
+
 // CreateDeckResponse is a struct that represents the JSON response for the createDeckHandler.
 type CreateDeckResponse struct {
 	DeckID    uuid.UUID `json:"deck_id"`
 	Shuffled  bool      `json:"shuffled"`
 	Remaining int       `json:"remaining"`
+	DeckCount int       `json:"deck_count"`
+	// Seed is the seed the deck was shuffled with. It is only present if it was provided on creation.
+	Seed *int64 `json:"seed,omitempty"`
+	// AutoReshuffle indicates whether the deck refills itself with its discard pile when it runs out of cards.
+	AutoReshuffle bool `json:"auto_reshuffle,omitempty"`
+	// Commitment is the hash commitment to the shuffled order of the deck. It is only present for decks shuffled
+	// without a seed. See GET /deck/:deck_id/proof.
+	Commitment string `json:"commitment,omitempty"`
+	// OwnerToken is the secret token of the deck's owner (e.g. the dealer). It is only returned on creation, and must
+	// be sent as "Authorization: Bearer <owner_token>" to see the order of the deck's cards.
+	OwnerToken string `json:"owner_token"`
+	// Owner is the name of the deck's owner. It is only present if it was provided on creation.
+	Owner string `json:"owner,omitempty"`
+	// Metadata is the data attached to the deck. It is only present if it was provided on creation.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// ExpiresAt is when the deck expires if it is not used until then. It is only present if the deck expires.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
@@ -3,10 +3,12 @@ package api
 import (
 	"deck-of-cards/card"
 	"encoding/json"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -60,20 +62,44 @@ func TestCreateDeckHandlerInvalidRequests(t *testing.T) {
 	router := setup()
 
 	testCases := []struct {
-		name       string
-		cardsParam string
+		name         string
+		cardsParam   string
+		expectedCode int
 	}{
 		{
-			name:       "cards param with no cards",
-			cardsParam: "",
+			name:         "cards param with no cards",
+			cardsParam:   "",
+			expectedCode: http.StatusUnprocessableEntity,
 		},
 		{
-			name:       "cards with invalid card code",
-			cardsParam: "INVALID_CARD",
+			name:         "cards with invalid card code",
+			cardsParam:   "INVALID_CARD",
+			expectedCode: http.StatusUnprocessableEntity,
 		},
 		{
-			name:       "cards with repeated codes",
-			cardsParam: "AS,AS",
+			name:         "cards with repeated codes",
+			cardsParam:   "AS,AS",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "cards repeated more than the deck count",
+			cardsParam:   "AS,AS,AS&deck_count=2",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "invalid deck count",
+			cardsParam:   "AS&deck_count=many",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "deck count too big",
+			cardsParam:   "AS&deck_count=1000",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid seed",
+			cardsParam:   "AS&seed=abc",
+			expectedCode: http.StatusBadRequest,
 		},
 	}
 
@@ -83,7 +109,7 @@ func TestCreateDeckHandlerInvalidRequests(t *testing.T) {
 			req := httptest.NewRequest(http.MethodPost, "/deck/new?cards="+tc.cardsParam, nil)
 			router.ServeHTTP(w, req)
 
-			assert.Equal(t, w.Code, http.StatusBadRequest)
+			assert.Equal(t, tc.expectedCode, w.Code)
 		})
 	}
 }
@@ -107,3 +133,362 @@ func TestCreateStandardDeckShuffled(t *testing.T) {
 	assert.True(t, resp.Shuffled)
 	assert.Equal(t, 52, resp.Remaining)
 }
+
+func TestCreateDeckComposition(t *testing.T) {
+	testCases := []struct {
+		name              string
+		params            string
+		expectedRemaining int
+		expectedDeckCount int
+	}{
+		{
+			name:              "standard deck",
+			params:            "",
+			expectedRemaining: 52,
+			expectedDeckCount: 1,
+		},
+		{
+			name:              "6-deck shoe",
+			params:            "?deck_count=6&shuffled=true",
+			expectedRemaining: 6 * 52,
+			expectedDeckCount: 6,
+		},
+		{
+			name:              "standard deck with Jokers",
+			params:            "?jokers_enabled=true",
+			expectedRemaining: 54,
+			expectedDeckCount: 1,
+		},
+		{
+			name:              "6-deck shoe with Jokers",
+			params:            "?deck_count=6&jokers_enabled=true",
+			expectedRemaining: 6 * 54,
+			expectedDeckCount: 6,
+		},
+		{
+			name:              "partial shoe with repeated cards",
+			params:            "?deck_count=2&cards=AS,AS,KD",
+			expectedRemaining: 3,
+			expectedDeckCount: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := setup()
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/deck/new"+tc.params, nil)
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var resp CreateDeckResponse
+			err := json.NewDecoder(w.Body).Decode(&resp)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedRemaining, resp.Remaining)
+			assert.Equal(t, tc.expectedDeckCount, resp.DeckCount)
+		})
+	}
+}
+
+func TestCreateDeckWithSeed(t *testing.T) {
+	router := setup()
+
+	openCards := func(params string) []card.Card {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/deck/new"+params, nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var createResponse CreateDeckResponse
+		err := json.NewDecoder(w.Body).Decode(&createResponse)
+		require.NoError(t, err)
+		require.NotNil(t, createResponse.Seed, "The seed is echoed back")
+		assert.Equal(t, int64(1234), *createResponse.Seed)
+		assert.True(t, createResponse.Shuffled, "A seeded deck is shuffled")
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s", createResponse.DeckID), nil)
+		req.Header.Set("Authorization", "Bearer "+createResponse.OwnerToken)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var openResponse OpenDeckResponse
+		err = json.NewDecoder(w.Body).Decode(&openResponse)
+		require.NoError(t, err)
+		return openResponse.Cards
+	}
+
+	assert.Equal(t, openCards("?seed=1234"), openCards("?seed=1234&shuffled=true"), "The same seed always yields the same order")
+	assert.Equal(t, openCards("?seed=1234&cards=AS,KD,AC,2C,KH"), openCards("?seed=1234&cards=AS,KD,AC,2C,KH"))
+}
+
+func TestCreateDeckWithoutSeed(t *testing.T) {
+	router := setup()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/deck/new?shuffled=true", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var createResponse CreateDeckResponse
+	err := json.NewDecoder(w.Body).Decode(&createResponse)
+	require.NoError(t, err)
+	assert.Nil(t, createResponse.Seed, "The seed is only echoed back when it was provided")
+}
+
+func TestCreateDeckWithJSONBody(t *testing.T) {
+	router := setup()
+
+	testCases := []struct {
+		name              string
+		body              string
+		expectedRemaining int
+		expectedShuffled  bool
+		expectedDeckCount int
+	}{
+		{
+			name:              "empty body",
+			body:              `{}`,
+			expectedRemaining: 52,
+			expectedDeckCount: 1,
+		},
+		{
+			name:              "partial deck",
+			body:              `{"cards": ["AS", "KD", "X1"], "shuffled": true}`,
+			expectedRemaining: 3,
+			expectedShuffled:  true,
+			expectedDeckCount: 1,
+		},
+		{
+			name:              "seeded shoe with jokers",
+			body:              `{"deck_count": 2, "jokers": true, "seed": 42}`,
+			expectedRemaining: 108,
+			expectedShuffled:  true,
+			expectedDeckCount: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/deck/new", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var resp CreateDeckResponse
+			err := json.NewDecoder(w.Body).Decode(&resp)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRemaining, resp.Remaining)
+			assert.Equal(t, tc.expectedShuffled, resp.Shuffled)
+			assert.Equal(t, tc.expectedDeckCount, resp.DeckCount)
+		})
+	}
+}
+
+func TestCreateDeckWithJSONContentTypeAndNoBody(t *testing.T) {
+	router := setup()
+
+	testCases := []struct {
+		name              string
+		target            string
+		expectedRemaining int
+		expectedShuffled  bool
+	}{
+		{
+			name:              "standard deck",
+			target:            "/deck/new",
+			expectedRemaining: 52,
+		},
+		{
+			name:              "query parameters",
+			target:            "/deck/new?deck_count=2&shuffled=true",
+			expectedRemaining: 104,
+			expectedShuffled:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tc.target, nil)
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, "Clients may send a JSON content type without a body")
+
+			var resp CreateDeckResponse
+			err := json.NewDecoder(w.Body).Decode(&resp)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRemaining, resp.Remaining)
+			assert.Equal(t, tc.expectedShuffled, resp.Shuffled)
+		})
+	}
+}
+
+func TestCreateDeckWithMetadata(t *testing.T) {
+	router := setup()
+
+	requests := map[string]*http.Request{
+		"json body": httptest.NewRequest(http.MethodPost, "/deck/new",
+			strings.NewReader(`{"owner": "alice", "metadata": {"game": "poker", "table": "7"}}`)),
+		"query": httptest.NewRequest(http.MethodPost, "/deck/new?owner=alice&metadata[game]=poker&metadata[table]=7", nil),
+	}
+	requests["json body"].Header.Set("Content-Type", "application/json")
+
+	for name, req := range requests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var createResponse CreateDeckResponse
+			err := json.NewDecoder(w.Body).Decode(&createResponse)
+			require.NoError(t, err)
+			assert.Equal(t, "alice", createResponse.Owner)
+			assert.Equal(t, map[string]string{"game": "poker", "table": "7"}, createResponse.Metadata)
+
+			// The metadata is kept with the deck.
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s", createResponse.DeckID), nil))
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var openResponse OpenDeckResponse
+			err = json.NewDecoder(w.Body).Decode(&openResponse)
+			require.NoError(t, err)
+			assert.Equal(t, createResponse.Metadata, openResponse.Metadata)
+		})
+	}
+}
+
+func TestCreateDeckBooleans(t *testing.T) {
+	router := setup()
+
+	testCases := []struct {
+		name             string
+		params           string
+		expectedCode     int
+		expectedShuffled bool
+	}{
+		{
+			name:             "true",
+			params:           "?shuffled=true",
+			expectedCode:     http.StatusOK,
+			expectedShuffled: true,
+		},
+		{
+			name:             "one",
+			params:           "?shuffled=1",
+			expectedCode:     http.StatusOK,
+			expectedShuffled: true,
+		},
+		{
+			name:             "upper case",
+			params:           "?shuffled=TRUE",
+			expectedCode:     http.StatusOK,
+			expectedShuffled: true,
+		},
+		{
+			name:         "false",
+			params:       "?shuffled=false",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "unparseable shuffled",
+			params:       "?shuffled=yes",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "unparseable jokers_enabled",
+			params:       "?jokers_enabled=maybe",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/deck/new"+tc.params, nil)
+			router.ServeHTTP(w, req)
+			require.Equal(t, tc.expectedCode, w.Code)
+
+			if tc.expectedCode == http.StatusOK {
+				var resp CreateDeckResponse
+				err := json.NewDecoder(w.Body).Decode(&resp)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedShuffled, resp.Shuffled)
+			}
+		})
+	}
+}
+
+func TestCreateDeckInvalidJSONBody(t *testing.T) {
+	router := setup()
+
+	testCases := []struct {
+		name              string
+		body              string
+		expectedCode      int
+		expectedParameter string
+	}{
+		{
+			name:         "malformed JSON",
+			body:         `{"shuffled": true`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:              "unparseable boolean",
+			body:              `{"shuffled": "yes"}`,
+			expectedCode:      http.StatusUnprocessableEntity,
+			expectedParameter: "shuffled",
+		},
+		{
+			name:              "unparseable seed",
+			body:              `{"seed": "abc"}`,
+			expectedCode:      http.StatusUnprocessableEntity,
+			expectedParameter: "seed",
+		},
+		{
+			name:              "owner too long",
+			body:              fmt.Sprintf(`{"owner": "%s"}`, strings.Repeat("a", 65)),
+			expectedCode:      http.StatusBadRequest,
+			expectedParameter: "owner",
+		},
+		{
+			name:              "metadata value too long",
+			body:              fmt.Sprintf(`{"metadata": {"game": "%s"}}`, strings.Repeat("a", 257)),
+			expectedCode:      http.StatusBadRequest,
+			expectedParameter: "metadata",
+		},
+		{
+			name:         "invalid card code",
+			body:         `{"cards": ["AS", "ZZ"]}`,
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:              "seed and client seed together",
+			body:              `{"seed": 1, "client_seed": "abc"}`,
+			expectedCode:      http.StatusBadRequest,
+			expectedParameter: "client_seed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/deck/new", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			require.Equal(t, tc.expectedCode, w.Code)
+
+			var errorResponse ErrorResponse
+			err := json.NewDecoder(w.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			if tc.expectedParameter != "" {
+				assert.Equal(t, tc.expectedParameter, errorResponse.Error.Details["parameter"])
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"deck-of-cards/card"
+	"deck-of-cards/deck"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// dealHandler is a Gin route handler for dealing cards from an existing deck to several players at once.
+// The players are provided as a comma-separated list of names in the "players" query parameter, and the number of
+// cards dealt to each of them as the "count" query parameter. The cards are placed on each player's pile (their hand).
+// By default, cards are dealt one at a time to each player in turn. With "round_robin=false", each player gets all
+// their cards in one go.
+// Either every player is dealt their cards, or nothing is dealt.
+// Only the owner of the deck (see ownerToken), the dealer, can deal.
+//
+// Example query parameters for dealing a Texas hold'em hand to three players:
+// /deck/:deck_id/deal?players=alice,bob,carol&count=2
+func (server *Server) dealHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	queryPlayers, exists := c.GetQuery("players")
+	if !exists {
+		respondWithError(c, invalidParameter("players", "players parameter must be provided."))
+		return
+	}
+	players := strings.Split(queryPlayers, ",")
+
+	countStr, exists := c.GetQuery("count")
+	if !exists {
+		respondWithError(c, invalidParameter("count", "count parameter must be provided."))
+		return
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		respondWithError(c, invalidParameter("count", "count parameter must be a positive integer"))
+		return
+	}
+
+	roundRobin := true
+	if _, exists := c.GetQuery("round_robin"); exists {
+		roundRobin, err = boolQuery(c, "round_robin")
+		if err != nil {
+			respondWithError(c, err)
+			return
+		}
+	}
+
+	var dealtCards map[string][]card.Card
+	updatedDeck, updated := server.updateDeck(c, deckID, func(d *deck.Deck) error {
+		if !isOwner(c, d) {
+			return forbidden("only the owner of the deck can deal it.")
+		}
+
+		var err error
+		dealtCards, err = d.Deal(players, count, roundRobin)
+		return err
+	})
+	if !updated {
+		return
+	}
+
+	hands := make([]Hand, len(players))
+	for i, player := range players {
+		hands[i] = Hand{
+			Player: player,
+			Cards:  dealtCards[player],
+		}
+	}
+
+	jsonResponse := DealResponse{
+		DeckID:    updatedDeck.ID,
+		Hands:     hands,
+		Remaining: updatedDeck.Remaining,
+		Version:   updatedDeck.Version,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// Hand holds the cards dealt to a player by the dealHandler, in the order they were dealt.
+type Hand struct {
+	// Player is the name of the player, and of the deck's pile holding their cards.
+	Player string      `json:"player"`
+	Cards  []card.Card `json:"cards"`
+}
+
+// DealResponse is a struct that represents the JSON response for the dealHandler. Hands are listed in the order of
+// the "players" query parameter.
+type DealResponse struct {
+	DeckID    uuid.UUID `json:"deck_id"`
+	Hands     []Hand    `json:"hands"`
+	Remaining int       `json:"remaining"`
+	Version   uint64    `json:"version"`
+}
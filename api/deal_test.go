@@ -0,0 +1,158 @@
+package api
+
+import (
+	"deck-of-cards/card"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeal(t *testing.T) {
+	testCases := []struct {
+		name          string
+		params        string
+		expectedHands []Hand
+	}{
+		{
+			name:   "round robin by default",
+			params: "?players=alice,bob,carol&count=2",
+			expectedHands: []Hand{
+				{Player: "alice", Cards: []card.Card{{Rank: card.Ace, Suit: card.Spades}, {Rank: card.Four, Suit: card.Spades}}},
+				{Player: "bob", Cards: []card.Card{{Rank: card.Two, Suit: card.Spades}, {Rank: card.Five, Suit: card.Spades}}},
+				{Player: "carol", Cards: []card.Card{{Rank: card.Three, Suit: card.Spades}, {Rank: card.Six, Suit: card.Spades}}},
+			},
+		},
+		{
+			name:   "in one go",
+			params: "?players=bob,alice&count=3&round_robin=false",
+			expectedHands: []Hand{
+				{Player: "bob", Cards: []card.Card{{Rank: card.Ace, Suit: card.Spades}, {Rank: card.Two, Suit: card.Spades}, {Rank: card.Three, Suit: card.Spades}}},
+				{Player: "alice", Cards: []card.Card{{Rank: card.Four, Suit: card.Spades}, {Rank: card.Five, Suit: card.Spades}, {Rank: card.Six, Suit: card.Spades}}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := setup()
+			deckID, token := createTestDeckWithToken(router, "?cards=AS,2S,3S,4S,5S,6S,7S")
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/deal%s", deckID, tc.params), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var dealResponse DealResponse
+			err := json.NewDecoder(w.Body).Decode(&dealResponse)
+			require.NoError(t, err)
+			assert.Equal(t, deckID, dealResponse.DeckID)
+			assert.Equal(t, tc.expectedHands, dealResponse.Hands)
+			assert.Equal(t, 1, dealResponse.Remaining)
+			assert.Equal(t, uint64(1), dealResponse.Version)
+
+			// Each hand is kept in the player's pile.
+			for _, hand := range tc.expectedHands {
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s/pile/%s", deckID, hand.Player), nil)
+				router.ServeHTTP(w, req)
+				require.Equal(t, http.StatusOK, w.Code)
+
+				var pileResponse PileResponse
+				err := json.NewDecoder(w.Body).Decode(&pileResponse)
+				require.NoError(t, err)
+				assert.ElementsMatch(t, hand.Cards, pileResponse.Cards)
+			}
+		})
+	}
+}
+
+func TestDealInvalidRequests(t *testing.T) {
+	router := setup()
+	deckID, deckToken := createTestDeckWithToken(router, "?cards=AS,2S,3S")
+
+	testCases := []struct {
+		name   string
+		deckID string
+		params string
+		// token is sent instead of the owner token of the deck, if it is not empty.
+		token        string
+		expectedCode int
+	}{
+		{
+			name:         "without players parameter",
+			deckID:       deckID.String(),
+			params:       "?count=1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "without count parameter",
+			deckID:       deckID.String(),
+			params:       "?players=alice",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "non-positive count",
+			deckID:       deckID.String(),
+			params:       "?players=alice&count=0",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid round_robin",
+			deckID:       deckID.String(),
+			params:       "?players=alice&count=1&round_robin=maybe",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "player listed twice",
+			deckID:       deckID.String(),
+			params:       "?players=alice,alice&count=1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "not enough cards for every player",
+			deckID:       deckID.String(),
+			params:       "?players=alice,bob&count=2",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "too many cards for the number of cards to be computed",
+			deckID:       deckID.String(),
+			params:       "?players=a,b,c,d&count=4611686018427387905&round_robin=false",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "not the owner",
+			deckID:       deckID.String(),
+			params:       "?players=alice&count=1",
+			token:        "not-the-owner-token",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "deck not found",
+			deckID:       uuid.NewString(),
+			params:       "?players=alice&count=1",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/deal%s", tc.deckID, tc.params), nil)
+			token := deckToken
+			if tc.token != "" {
+				token = tc.token
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code, "Expected status code to match")
+		})
+	}
+}
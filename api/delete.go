@@ -0,0 +1,63 @@
+package api
+
+import (
+	"deck-of-cards/deck"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+)
+
+// deleteDeckHandler is a Gin route handler for deleting an existing deck (e.g. when a game ends).
+// It responds with 204 No Content if the deck was deleted, or 404 Not Found if there is no such deck.
+func (server *Server) deleteDeckHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	err = server.store.Remove(deckID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// deleteDecksHandler is a Gin route handler for deleting every deck older than the duration given in the
+// "older_than" query parameter (e.g. "24h", see time.ParseDuration). Only the administrator of the server can use it.
+// The number of deleted decks is returned as JSON.
+//
+// Example:
+// DELETE /decks?older_than=72h
+func (server *Server) deleteDecksHandler(c *gin.Context) {
+	if server.adminToken == "" {
+		respondWithError(c, errAdminDisabled)
+		return
+	}
+	if !server.isAdmin(c) {
+		respondWithError(c, forbidden("only the administrator can delete decks in bulk."))
+		return
+	}
+
+	olderThan, err := time.ParseDuration(c.Query("older_than"))
+	if err != nil || olderThan <= 0 {
+		respondWithError(c, invalidParameter("older_than", "older_than parameter must be a positive duration (e.g. 24h)"))
+		return
+	}
+
+	deleted, err := server.store.RemoveMatching(deck.DeckFilter{CreatedBefore: time.Now().Add(-olderThan)})
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, DeleteDecksResponse{Deleted: deleted})
+}
+
+// DeleteDecksResponse is a struct that represents the JSON response for the deleteDecksHandler.
+type DeleteDecksResponse struct {
+	Deleted int `json:"deleted"`
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"deck-of-cards/deck"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeleteDeck(t *testing.T) {
+	router := setup()
+
+	deckID := createTestDeck(router, "")
+
+	testCases := []struct {
+		name         string
+		deckID       string
+		expectedCode int
+	}{
+		{
+			name:         "invalid deck ID",
+			deckID:       "invalid-deck-id",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "deck not found",
+			deckID:       uuid.NewString(),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "existing deck",
+			deckID:       deckID.String(),
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "already deleted",
+			deckID:       deckID.String(),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/deck/%s", tc.deckID), nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+
+	// Deleted decks can not be used anymore.
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=1", deckID), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteDecksOlderThan(t *testing.T) {
+	store := deck.NewStore()
+	router := setup(WithRepository(store), WithAdminToken("admin-token"))
+
+	oldDeck := deck.NewStandardDeck()
+	oldDeck.CreatedAt = time.Now().Add(-48 * time.Hour)
+	require.NoError(t, store.Add(&oldDeck))
+	newDeckID := createTestDeck(router, "")
+
+	testCases := []struct {
+		name         string
+		olderThan    string
+		token        string
+		expectedCode int
+	}{
+		{
+			name:         "no token",
+			olderThan:    "24h",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "owner token",
+			olderThan:    "24h",
+			token:        "not-the-admin-token",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "no older_than parameter",
+			token:        "admin-token",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid older_than parameter",
+			olderThan:    "yesterday",
+			token:        "admin-token",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "non-positive older_than parameter",
+			olderThan:    "-1h",
+			token:        "admin-token",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, "/decks?older_than="+tc.olderThan, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/decks?older_than=24h", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var deleteResponse DeleteDecksResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&deleteResponse))
+	assert.Equal(t, 1, deleteResponse.Deleted)
+
+	_, err := store.Get(oldDeck.ID)
+	assert.ErrorIs(t, err, deck.ErrDeckNotFound, "The old deck is deleted")
+	_, err = store.Get(newDeckID)
+	assert.NoError(t, err, "The new deck is kept")
+}
+
+func TestDeleteDecksWithoutAdminToken(t *testing.T) {
+	router := setup()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/decks?older_than=24h", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code, "Admin endpoints are disabled without an admin token")
+}
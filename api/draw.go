@@ -2,52 +2,134 @@ package api
 
 import (
 	"deck-of-cards/card"
+	"deck-of-cards/deck"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 // drawCardHandler is a Gin route handler for drawing a specified number of cards from an existing deck.
 // The deck ID and card count are provided as URL parameters. If the deck is found and the draw is successful,
 // the drawn cards are returned as JSON.
+// The optional "from" query parameter tells where the cards are drawn from: "top" (the default), "bottom", or
+// "random". Instead of a count, the "cards" query parameter draws the listed cards, wherever they are in the deck.
+// The optional "pile" query parameter places the drawn cards directly on the named pile of the deck.
+// By default, nothing is drawn if the deck has fewer cards than requested. With "allow_partial=true", the cards left
+// are drawn instead, and the response tells whether the deck is exhausted.
+//
+// Example query parameters for burning the bottom card:
+// /deck/:deck_id/draw?count=1&from=bottom
+//
+// Example query parameters for drawing two given cards:
+// /deck/:deck_id/draw?cards=AS,KD
 func (server *Server) drawCardHandler(c *gin.Context) {
 	deckID, err := uuid.Parse(c.Param("deck_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "deck ID is not valid."})
+		respondWithError(c, errInvalidDeckID)
 		return
 	}
 
-	countStr, exists := c.GetQuery("count")
-	if !exists {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "count parameter must be provided."})
+	draw, err := drawFunc(c)
+	if err != nil {
+		respondWithError(c, err)
 		return
 	}
-	count, err := strconv.Atoi(countStr)
-	if err != nil || count <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "count parameter must be a positive integer"})
+
+	drawnCards := []card.Card{}
+	updatedDeck, updated := server.updateDeck(c, deckID, func(d *deck.Deck) error {
+		cards, err := draw(d)
+		if err != nil {
+			return err
+		}
+		if len(cards) == 0 {
+			// Nothing was drawn from the exhausted deck (see allow_partial).
+			return errUnchanged
+		}
+		drawnCards = cards
+
+		if pileName, toPile := c.GetQuery("pile"); toPile {
+			return d.AddToPile(pileName, drawnCards)
+		}
+		return nil
+	})
+	if !updated {
 		return
 	}
 
-	deckRetrieved, notFound := server.store.Get(deckID)
-	if notFound != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "deck not found. Are you sure deck_id is correct?"})
-		return
+	jsonResponse := DrawCardsResponse{
+		DeckID:    updatedDeck.ID,
+		Cards:     drawnCards,
+		Remaining: updatedDeck.Remaining,
+		Shuffled:  updatedDeck.Shuffled,
+		Version:   updatedDeck.Version,
+		Exhausted: updatedDeck.Drawable() == 0,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// drawFunc returns the function drawing the cards requested by the query parameters of drawCardHandler from a deck.
+func drawFunc(c *gin.Context) (func(d *deck.Deck) ([]card.Card, error), error) {
+	queryCards, drawSome := c.GetQuery("cards")
+	_, hasCount := c.GetQuery("count")
+	_, hasFrom := c.GetQuery("from")
+	if drawSome {
+		if hasCount || hasFrom {
+			return nil, invalidParameter("cards", "cards parameter can not be used together with count or from")
+		}
+		return func(d *deck.Deck) ([]card.Card, error) {
+			return d.DrawCards(strings.Split(queryCards, ","))
+		}, nil
+	}
+
+	countStr, exists := c.GetQuery("count")
+	if !exists {
+		return nil, invalidParameter("count", "count parameter must be provided.")
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		return nil, invalidParameter("count", "count parameter must be a positive integer")
 	}
 
-	drawnCards, err := deckRetrieved.Draw(count)
+	allowPartial, err := boolQuery(c, "allow_partial")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return nil, err
 	}
 
-	jsonResponse := DrawCardsResponse{
-		Cards: drawnCards,
+	var drawFrom func(d *deck.Deck, count int) ([]card.Card, error)
+	switch from := c.DefaultQuery("from", "top"); from {
+	case "top":
+		drawFrom = (*deck.Deck).Draw
+	case "bottom":
+		drawFrom = (*deck.Deck).DrawBottom
+	case "random":
+		drawFrom = (*deck.Deck).DrawRandom
+	default:
+		return nil, invalidParameter("from", "from parameter must be one of top, bottom or random")
 	}
-	c.JSON(http.StatusOK, jsonResponse)
+
+	return func(d *deck.Deck) ([]card.Card, error) {
+		drawCount := count
+		if allowPartial && !d.Closed && drawCount > d.Drawable() {
+			drawCount = d.Drawable()
+			if drawCount == 0 {
+				return nil, nil
+			}
+		}
+		return drawFrom(d, drawCount)
+	}, nil
 }
 
-// DrawCardsResponse is a struct that represents the JSON response for the drawCardHandler.
+// DrawCardsResponse is a struct that represents the JSON response for the drawCardHandler. It holds the drawn cards,
+// and the state of the deck after the draw.
 type DrawCardsResponse struct {
-	Cards []card.Card `json:"cards"`
+	DeckID    uuid.UUID   `json:"deck_id"`
+	Cards     []card.Card `json:"cards"`
+	Remaining int         `json:"remaining"`
+	Shuffled  bool        `json:"shuffled"`
+	// Version is incremented each time the deck changes, so clients can detect changes made by other clients.
+	Version uint64 `json:"version"`
+	// Exhausted indicates whether the deck has no more cards to draw.
+	Exhausted bool `json:"exhausted"`
 }
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 )
 
@@ -50,7 +52,7 @@ func TestDrawCardHandler(t *testing.T) {
 			name:         "deck not found",
 			deckID:       uuid.NewString(),
 			count:        "5",
-			expectedCode: http.StatusBadRequest,
+			expectedCode: http.StatusNotFound,
 		},
 	}
 
@@ -120,3 +122,296 @@ func TestDrawPartialDeck(t *testing.T) {
 	assert.Equal(t, drawnCards[1], expectedCards[3], "Next card drawn is the (currently) first in the deck.")
 	assert.Equal(t, drawnCards[2], expectedCards[4], "Next card drawn is the (currently) first in the deck.")
 }
+
+func TestConcurrentDraws(t *testing.T) {
+	router := setup()
+
+	deckID, token := createTestDeckWithToken(router, "?shuffled=true")
+
+	// Every card of the deck is drawn, while the deck is opened and its remaining cards are shuffled.
+	const goroutines = 26
+	drawn := make(chan card.Card, 52)
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=2", deckID), nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var drawResponse DrawCardsResponse
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&drawResponse))
+			for _, c := range drawResponse.Cards {
+				drawn <- c
+			}
+
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s", deckID), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/shuffle?remaining=true", deckID), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+		}()
+	}
+	wg.Wait()
+	close(drawn)
+
+	dealt := make(map[card.Card]bool)
+	for c := range drawn {
+		assert.False(t, dealt[c], "card %s was dealt twice", c)
+		dealt[c] = true
+	}
+	assert.Len(t, dealt, 52)
+
+	var openResponse OpenDeckResponse
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s", deckID), nil)
+	router.ServeHTTP(w, req)
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&openResponse))
+	assert.Equal(t, 0, openResponse.Remaining)
+}
+
+func TestDrawAllowPartial(t *testing.T) {
+	router := setup()
+
+	deckID := createTestDeck(router, "?cards=AS,KD,QH")
+
+	draw := func(params string) (int, DrawCardsResponse) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw%s", deckID, params), nil)
+		router.ServeHTTP(w, req)
+
+		var drawResponse DrawCardsResponse
+		_ = json.NewDecoder(w.Body).Decode(&drawResponse)
+		return w.Code, drawResponse
+	}
+
+	// Without allow_partial, drawing more cards than remaining fails, and draws nothing.
+	code, _ := draw("?count=5")
+	assert.Equal(t, http.StatusConflict, code)
+
+	code, drawResponse := draw("?count=2&allow_partial=true")
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, drawResponse.Cards, 2)
+	assert.Equal(t, 1, drawResponse.Remaining)
+	assert.False(t, drawResponse.Exhausted)
+
+	code, drawResponse = draw("?count=5&allow_partial=true")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []card.Card{{Rank: card.Queen, Suit: card.Hearts}}, drawResponse.Cards)
+	assert.Equal(t, 0, drawResponse.Remaining)
+	assert.True(t, drawResponse.Exhausted)
+
+	// Drawing from an exhausted deck returns no cards, and does not change the deck.
+	version := drawResponse.Version
+	code, drawResponse = draw("?count=1&allow_partial=true")
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, drawResponse.Cards)
+	assert.True(t, drawResponse.Exhausted)
+	assert.Equal(t, version, drawResponse.Version)
+
+	code, _ = draw("?count=1&allow_partial=maybe")
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+}
+
+func TestDrawAutoReshuffle(t *testing.T) {
+	router := setup()
+
+	deckID := createTestDeck(router, "?cards=AS,KD,QH&auto_reshuffle=true")
+
+	draw := func(params string) DrawCardsResponse {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw%s", deckID, params), nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var drawResponse DrawCardsResponse
+		err := json.NewDecoder(w.Body).Decode(&drawResponse)
+		require.NoError(t, err)
+		return drawResponse
+	}
+
+	// Two cards are discarded: the deck can be refilled with them once it runs out.
+	drawResponse := draw("?count=2&pile=discard")
+	assert.Equal(t, 1, drawResponse.Remaining)
+	assert.False(t, drawResponse.Exhausted)
+
+	drawResponse = draw("?count=3")
+	assert.Len(t, drawResponse.Cards, 3)
+	assert.Equal(t, 0, drawResponse.Remaining)
+	assert.True(t, drawResponse.Exhausted)
+}
+
+func TestDrawResponseHoldsDeckState(t *testing.T) {
+	router := setup()
+
+	deckID := createTestDeck(router, "?shuffled=true")
+
+	for version := uint64(1); version <= 2; version++ {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=2", deckID), nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var drawResponse DrawCardsResponse
+		err := json.NewDecoder(w.Body).Decode(&drawResponse)
+		require.NoError(t, err)
+		assert.Equal(t, deckID, drawResponse.DeckID)
+		assert.Equal(t, 52-2*int(version), drawResponse.Remaining)
+		assert.True(t, drawResponse.Shuffled)
+		assert.Equal(t, version, drawResponse.Version, "Each draw increments the version of the deck")
+	}
+
+	// Failed draws do not change the deck.
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=100", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var openResponse OpenDeckResponse
+	err := json.NewDecoder(w.Body).Decode(&openResponse)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), openResponse.Version)
+}
+
+func TestDrawFrom(t *testing.T) {
+	testCases := []struct {
+		name              string
+		params            string
+		expectedCards     []card.Card
+		expectedRemaining int
+	}{
+		{
+			name:              "from top",
+			params:            "?count=1&from=top",
+			expectedCards:     []card.Card{{Rank: card.Queen, Suit: card.Hearts}},
+			expectedRemaining: 3,
+		},
+		{
+			name:              "from bottom",
+			params:            "?count=2&from=bottom",
+			expectedCards:     []card.Card{{Rank: card.King, Suit: card.Hearts}, {Rank: card.Ace, Suit: card.Clubs}},
+			expectedRemaining: 2,
+		},
+		{
+			name:              "specific cards",
+			params:            "?cards=KH,4D",
+			expectedCards:     []card.Card{{Rank: card.King, Suit: card.Hearts}, {Rank: card.Four, Suit: card.Diamonds}},
+			expectedRemaining: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := setup()
+			deckID := createTestDeck(router, "?cards=QH,4D,AC,KH")
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw%s", deckID, tc.params), nil)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var drawResponse DrawCardsResponse
+			err := json.NewDecoder(w.Body).Decode(&drawResponse)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCards, drawResponse.Cards)
+			assert.Equal(t, tc.expectedRemaining, drawResponse.Remaining)
+		})
+	}
+}
+
+func TestDrawRandomToPile(t *testing.T) {
+	router := setup()
+	deckID := createTestDeck(router, "")
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=5&from=random&pile=alice", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var drawResponse DrawCardsResponse
+	err := json.NewDecoder(w.Body).Decode(&drawResponse)
+	require.NoError(t, err)
+	assert.Len(t, drawResponse.Cards, 5)
+	assert.Equal(t, 47, drawResponse.Remaining)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s/pile/alice", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var pileResponse PileResponse
+	err = json.NewDecoder(w.Body).Decode(&pileResponse)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, drawResponse.Cards, pileResponse.Cards)
+}
+
+func TestDrawFromInvalidRequests(t *testing.T) {
+	router := setup()
+	deckID := createTestDeck(router, "?cards=QH,4D,AC,KH")
+
+	testCases := []struct {
+		name         string
+		params       string
+		expectedCode int
+		expectedErr  string
+	}{
+		{
+			name:         "unknown from",
+			params:       "?count=1&from=middle",
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  "invalid_parameter",
+		},
+		{
+			name:         "cards and count together",
+			params:       "?count=1&cards=QH",
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  "invalid_parameter",
+		},
+		{
+			name:         "card not in the deck",
+			params:       "?cards=QH,AS",
+			expectedCode: http.StatusConflict,
+			expectedErr:  "card_not_in_deck",
+		},
+		{
+			name:         "invalid card code",
+			params:       "?cards=ZZ",
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedErr:  "invalid_card_code",
+		},
+		{
+			name:         "too many cards from the bottom",
+			params:       "?count=5&from=bottom",
+			expectedCode: http.StatusConflict,
+			expectedErr:  "not_enough_cards",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw%s", deckID, tc.params), nil)
+			router.ServeHTTP(w, req)
+			require.Equal(t, tc.expectedCode, w.Code)
+
+			var errorResponse ErrorResponse
+			err := json.NewDecoder(w.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedErr, errorResponse.Error.Code)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"deck-of-cards/deck"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// problemContentType is the media type of error responses (RFC 7807).
+const problemContentType = "application/problem+json"
+
+// apiError is an error caused by the request, with the HTTP status and the error code to respond with.
+type apiError struct {
+	status  int
+	code    string
+	message string
+	details map[string]any
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+// invalidParameter returns the error for a missing or invalid query parameter (or field of the request body).
+func invalidParameter(parameter, message string) *apiError {
+	return &apiError{
+		status:  http.StatusBadRequest,
+		code:    "invalid_parameter",
+		message: message,
+		details: map[string]any{"parameter": parameter},
+	}
+}
+
+// unprocessableParameter returns the error for a query parameter (or field of the request body) which can not be
+// parsed as its type (e.g. a boolean which is neither true nor false).
+func unprocessableParameter(parameter, message string) *apiError {
+	return &apiError{
+		status:  http.StatusUnprocessableEntity,
+		code:    "invalid_parameter",
+		message: message,
+		details: map[string]any{"parameter": parameter},
+	}
+}
+
+// invalidRequest returns the error for a request which could not be bound to request, a struct with json tags (see
+// gin.Context.ShouldBindJSON). Fields are named after their json tag.
+func invalidRequest(request any, err error) error {
+	var typeErr *json.UnmarshalTypeError
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.As(err, &typeErr):
+		return unprocessableParameter(typeErr.Field, fmt.Sprintf("%s parameter must be of type %s", typeErr.Field, typeErr.Type))
+	case errors.As(err, &validationErrs):
+		fieldErr := validationErrs[0]
+		parameter := jsonFieldName(request, fieldErr.StructField())
+		return invalidParameter(parameter, fmt.Sprintf("%s parameter does not satisfy %s", parameter, validationRule(fieldErr)))
+	case errors.As(err, new(*apiError)):
+		return err
+	default:
+		return &apiError{status: http.StatusBadRequest, code: "invalid_body", message: "request body is not valid JSON: " + err.Error()}
+	}
+}
+
+// jsonFieldName returns the name of the field of request (a struct) in JSON, e.g. "metadata" for "Metadata[game]".
+func jsonFieldName(request any, field string) string {
+	field, _, _ = strings.Cut(field, "[")
+	structField, ok := reflect.TypeOf(request).FieldByName(field)
+	if !ok {
+		return field
+	}
+	name, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+	return name
+}
+
+// validationRule describes the binding rule a field does not satisfy, e.g. "max=64".
+func validationRule(fieldErr validator.FieldError) string {
+	if fieldErr.Param() == "" {
+		return fieldErr.Tag()
+	}
+	return fieldErr.Tag() + "=" + fieldErr.Param()
+}
+
+// errInvalidDeckID is the error for a deck ID which is not a valid UUID.
+var errInvalidDeckID = &apiError{status: http.StatusBadRequest, code: "invalid_deck_id", message: "deck ID is not valid."}
+
+// errAdminDisabled is the error for admin endpoints, when the server has no admin token (see WithAdminToken).
+var errAdminDisabled = &apiError{status: http.StatusNotFound, code: "admin_disabled", message: "admin endpoints are disabled."}
+
+// forbidden returns the error for a request which is not allowed with the token it was sent with (if any).
+func forbidden(message string) *apiError {
+	return &apiError{status: http.StatusForbidden, code: "forbidden", message: message}
+}
+
+// rejectedError is an error returned by the deck package because of the request (e.g. an invalid card code), rather
+// than because of the server. Unless it maps to a more specific error (see deckErrors), it is a bad request.
+type rejectedError struct {
+	err error
+}
+
+func (e rejectedError) Error() string {
+	return e.err.Error()
+}
+
+func (e rejectedError) Unwrap() error {
+	return e.err
+}
+
+// rejected marks err as caused by the request.
+func rejected(err error) error {
+	return rejectedError{err: err}
+}
+
+// deckErrors maps the errors of the deck package to the HTTP status and error code to respond with.
+var deckErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{deck.ErrDeckNotFound, http.StatusNotFound, "deck_not_found"},
+	{deck.ErrDeckExpired, http.StatusGone, "deck_expired"},
+	{deck.ErrPileNotFound, http.StatusNotFound, "pile_not_found"},
+	{deck.ErrNotEnoughCards, http.StatusConflict, "not_enough_cards"},
+	{deck.ErrDeckClosed, http.StatusConflict, "deck_closed"},
+	{deck.ErrCardNotDrawn, http.StatusConflict, "card_not_drawn"},
+	{deck.ErrCardNotInDeck, http.StatusConflict, "card_not_in_deck"},
+	{deck.ErrInvalidPosition, http.StatusConflict, "invalid_position"},
+	{deck.ErrProofUnavailable, http.StatusConflict, "proof_unavailable"},
+	{deck.ErrNothingToUndo, http.StatusConflict, "nothing_to_undo"},
+	{deck.ErrNothingToRedo, http.StatusConflict, "nothing_to_redo"},
+	{deck.ErrOrderRevealed, http.StatusConflict, "order_revealed"},
+	{deck.ErrInvalidCardCode, http.StatusUnprocessableEntity, "invalid_card_code"},
+	{deck.ErrDuplicateCard, http.StatusUnprocessableEntity, "duplicate_card"},
+	{deck.ErrStoreFull, http.StatusInsufficientStorage, "store_full"},
+	{deck.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+}
+
+// toAPIError returns the apiError to respond with for err. Errors which are not known to be caused by the request
+// are internal server errors, and their message is not shown to the client.
+func toAPIError(err error) *apiError {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	for _, deckErr := range deckErrors {
+		if errors.Is(err, deckErr.err) {
+			return &apiError{status: deckErr.status, code: deckErr.code, message: err.Error()}
+		}
+	}
+
+	if errors.As(err, &rejectedError{}) {
+		return &apiError{status: http.StatusBadRequest, code: "bad_request", message: err.Error()}
+	}
+
+	return &apiError{status: http.StatusInternalServerError, code: "internal_error", message: "internal server error"}
+}
+
+// respondWithError writes the error response for err, and aborts the request. The response is an RFC 7807 problem
+// details object, holding the error code and message as "error":
+//
+//	{
+//	  "type": "about:blank",
+//	  "title": "Not Found",
+//	  "status": 404,
+//	  "detail": "deck not found",
+//	  "error": {"code": "deck_not_found", "message": "deck not found"}
+//	}
+func respondWithError(c *gin.Context, err error) {
+	apiErr := toAPIError(err)
+	if apiErr.status == http.StatusInternalServerError {
+		// Kept for the logs, as the client does not see it.
+		_ = c.Error(err)
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(apiErr.status, ErrorResponse{
+		Type:   "about:blank",
+		Title:  http.StatusText(apiErr.status),
+		Status: apiErr.status,
+		Detail: apiErr.message,
+		Error: ErrorBody{
+			Code:    apiErr.code,
+			Message: apiErr.message,
+			Details: apiErr.details,
+		},
+	})
+}
+
+// ErrorResponse is a struct that represents the JSON response for every error (an RFC 7807 problem details object).
+type ErrorResponse struct {
+	Type   string    `json:"type"`
+	Title  string    `json:"title"`
+	Status int       `json:"status"`
+	Detail string    `json:"detail"`
+	Error  ErrorBody `json:"error"`
+}
+
+// ErrorBody describes an error: a stable, machine-readable code (e.g. "deck_not_found"), a message for humans, and
+// details depending on the error (e.g. which parameter is invalid).
+type ErrorBody struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
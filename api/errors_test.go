@@ -0,0 +1,120 @@
+package api
+
+import (
+	"deck-of-cards/deck"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorResponse(t *testing.T) {
+	router := setup()
+
+	validID := createTestDeck(router, "?cards=AS,2S")
+
+	testCases := []struct {
+		name            string
+		method          string
+		target          string
+		expectedStatus  int
+		expectedCode    string
+		expectedDetails map[string]any
+	}{
+		{
+			name:           "deck not found",
+			method:         http.MethodGet,
+			target:         fmt.Sprintf("/deck/%s", uuid.NewString()),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "deck_not_found",
+		},
+		{
+			name:           "invalid deck ID",
+			method:         http.MethodGet,
+			target:         "/deck/invalid-deck-id",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "invalid_deck_id",
+		},
+		{
+			name:            "invalid parameter",
+			method:          http.MethodPost,
+			target:          fmt.Sprintf("/deck/%s/draw?count=zero", validID),
+			expectedStatus:  http.StatusBadRequest,
+			expectedCode:    "invalid_parameter",
+			expectedDetails: map[string]any{"parameter": "count"},
+		},
+		{
+			name:           "not enough cards",
+			method:         http.MethodPost,
+			target:         fmt.Sprintf("/deck/%s/draw?count=3", validID),
+			expectedStatus: http.StatusConflict,
+			expectedCode:   "not_enough_cards",
+		},
+		{
+			name:           "invalid card code",
+			method:         http.MethodPost,
+			target:         "/deck/new?cards=AS,ZZ",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "invalid_card_code",
+		},
+		{
+			name:           "duplicate card",
+			method:         http.MethodPost,
+			target:         "/deck/new?cards=AS,AS",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "duplicate_card",
+		},
+		{
+			name:           "pile not found",
+			method:         http.MethodGet,
+			target:         fmt.Sprintf("/deck/%s/pile/unknown", validID),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "pile_not_found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+			var errorResponse ErrorResponse
+			err := json.NewDecoder(w.Body).Decode(&errorResponse)
+			require.NoError(t, err)
+			assert.Equal(t, "about:blank", errorResponse.Type)
+			assert.Equal(t, http.StatusText(tc.expectedStatus), errorResponse.Title)
+			assert.Equal(t, tc.expectedStatus, errorResponse.Status)
+			assert.Equal(t, tc.expectedCode, errorResponse.Error.Code)
+			assert.NotEmpty(t, errorResponse.Error.Message)
+			assert.Equal(t, errorResponse.Error.Message, errorResponse.Detail)
+			assert.Equal(t, tc.expectedDetails, errorResponse.Error.Details)
+		})
+	}
+}
+
+func TestToAPIError(t *testing.T) {
+	// Wrapped deck errors are still recognized.
+	apiErr := toAPIError(fmt.Errorf("drawing: %w", deck.ErrNotEnoughCards))
+	assert.Equal(t, http.StatusConflict, apiErr.status)
+	assert.Equal(t, "not_enough_cards", apiErr.code)
+
+	// Unknown errors from the request are bad requests.
+	apiErr = toAPIError(rejected(errors.New("no cards")))
+	assert.Equal(t, http.StatusBadRequest, apiErr.status)
+	assert.Equal(t, "no cards", apiErr.message)
+
+	// Other errors are internal server errors, and their message is hidden from the client.
+	apiErr = toAPIError(errors.New("disk on fire"))
+	assert.Equal(t, http.StatusInternalServerError, apiErr.status)
+	assert.Equal(t, "internal_error", apiErr.code)
+	assert.NotContains(t, apiErr.message, "disk on fire")
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"deck-of-cards/card"
+	"deck-of-cards/deck"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// maxHistoryLimit is the default and maximum number of events listed by historyHandler at once.
+const maxHistoryLimit = 1000
+
+// historyHandler is a Gin route handler for listing the history of an existing deck: every operation on the deck
+// since its creation (shuffles, draws, returns, insertions, ...), when it happened and who performed it (see
+// deck.Event), so disputes about what was dealt can be settled.
+// The order of the cards after an operation (e.g. a shuffle), and the cards inserted in the deck with their position,
+// are only listed to the owner of the deck, as they tell which cards are coming next (see openDeckHandler).
+// The response also tells whether replaying the history leads to the current state of the deck (see
+// deck.Deck.VerifyHistory).
+//
+// At most "limit" events are listed at once (1000 by default), from the event after the "after" sequence (0 by
+// default). If there are more events, the response holds a "next_after", to be sent as the "after" query parameter to
+// list the next ones. The oldest events of a long history are read from the archive of the store (see
+// deck.EventCompacted).
+//
+// Example:
+// GET /deck/:deck_id/history?after=1000&limit=100
+func (server *Server) historyHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	afterQuery, valid := optionalCountQuery(c, "after")
+	if !valid {
+		return
+	}
+	after := 0
+	if afterQuery != nil {
+		after = *afterQuery
+	}
+	limit := maxHistoryLimit
+	if limitStr, exists := c.GetQuery("limit"); exists {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxHistoryLimit {
+			respondWithError(c, invalidParameter("limit", fmt.Sprintf("limit parameter must be an integer between 1 and %d", maxHistoryLimit)))
+			return
+		}
+	}
+
+	deckRetrieved, found := server.getDeck(c, deckID)
+	if !found {
+		return
+	}
+
+	// The compacted event stands for the archived events, which are listed instead.
+	history := deckRetrieved.History
+	archived := 0
+	if len(history) > 0 && history[0].Type == deck.EventCompacted {
+		archived = history[0].Sequence
+		history = history[1:]
+	}
+
+	var page []deck.Event
+	if after < archived {
+		page, err = server.store.ArchivedHistory(deckID, after, limit+1)
+		if err != nil {
+			respondWithError(c, err)
+			return
+		}
+		// Events archived since the deck was retrieved are still in its History.
+		for len(page) > 0 && page[len(page)-1].Sequence > archived {
+			page = page[:len(page)-1]
+		}
+	}
+	start := sort.Search(len(history), func(i int) bool {
+		return history[i].Sequence > after
+	})
+	page = append(page, history[start:]...)
+
+	var nextAfter int
+	if len(page) > limit {
+		page = page[:limit]
+		nextAfter = page[limit-1].Sequence
+	}
+
+	showOrder := isOwner(c, deckRetrieved)
+	events := make([]HistoryEvent, len(page))
+	for i, event := range page {
+		events[i] = HistoryEvent{
+			Sequence: event.Sequence,
+			Version:  event.Version,
+			Type:     string(event.Type),
+			Time:     event.Time,
+			Actor:    event.Actor,
+			Cards:    event.Cards,
+			Pile:     event.Pile,
+			Method:   event.Method,
+			Versions: event.Versions,
+		}
+		if showOrder {
+			events[i].Order = event.Order
+			if event.Type == deck.EventInserted {
+				events[i].Position = positionString(event.Position)
+			}
+		} else if event.Type == deck.EventInserted {
+			events[i].Cards = nil
+		}
+	}
+
+	jsonResponse := HistoryResponse{
+		DeckID:    deckRetrieved.ID,
+		Events:    events,
+		NextAfter: nextAfter,
+		Version:   deckRetrieved.Version,
+		Verified:  deckRetrieved.VerifyHistory() == nil,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// positionString returns the position as the "position" query parameter of insertCardsHandler (see positionQuery).
+func positionString(position deck.Position) string {
+	switch position {
+	case deck.PositionTop:
+		return "top"
+	case deck.PositionBottom:
+		return "bottom"
+	case deck.PositionRandom:
+		return "random"
+	default:
+		return strconv.Itoa(int(position))
+	}
+}
+
+// HistoryEvent is an operation on a deck, as listed by the historyHandler (see deck.Event).
+type HistoryEvent struct {
+	// Sequence is the position of the event in the history of the deck, starting at 1.
+	Sequence int `json:"sequence"`
+	// Version is the version of the deck made by the operation. The events of the same request share it.
+	Version uint64 `json:"version"`
+	Type    string `json:"type"`
+	// Time is when the operation happened.
+	Time time.Time `json:"time"`
+	// Actor is who performed the operation: "owner", "admin" or "anonymous".
+	Actor string      `json:"actor"`
+	Cards []card.Card `json:"cards,omitempty"`
+	Pile  string      `json:"pile,omitempty"`
+	// Method is how the deck was shuffled (e.g. "uniform" or "riffle").
+	Method string `json:"method,omitempty"`
+	// Position is where the cards were inserted (see insertCardsHandler). Like the inserted cards, it is only listed
+	// to the owner of the deck.
+	Position string `json:"position,omitempty"`
+	// Order holds the remaining cards of the deck (or of the pile) after the operation, if the caller is allowed to see
+	// them (see historyHandler).
+	Order []card.Card `json:"order,omitempty"`
+	// Versions holds the versions of the operations undone or redone (see undoHandler).
+	Versions []uint64 `json:"versions,omitempty"`
+}
+
+// HistoryResponse is a struct that represents the JSON response for the historyHandler.
+type HistoryResponse struct {
+	DeckID uuid.UUID      `json:"deck_id"`
+	Events []HistoryEvent `json:"events"`
+	// NextAfter is the sequence of the last listed event, to be sent as the "after" query parameter to list the next
+	// events. It is only present if there are more events.
+	NextAfter int `json:"next_after,omitempty"`
+	// Version is incremented each time the deck changes (see DrawCardsResponse).
+	Version uint64 `json:"version"`
+	// Verified tells whether replaying the events leads to the current state of the deck.
+	Verified bool `json:"verified"`
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"deck-of-cards/card"
+	"deck-of-cards/deck"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	router := setup()
+	deckID, token := createTestDeckWithToken(router, "?cards=AS,KD,AC,2C&seed=42")
+
+	requests := []struct {
+		target string
+		token  string
+	}{
+		{target: fmt.Sprintf("/deck/%s/draw?cards=AS,KD", deckID)},
+		{target: fmt.Sprintf("/deck/%s/return?cards=AS", deckID), token: token},
+		{target: fmt.Sprintf("/deck/%s/insert?cards=X1&position=top", deckID), token: token},
+	}
+	for _, request := range requests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, request.target, nil)
+		if request.token != "" {
+			req.Header.Set("Authorization", "Bearer "+request.token)
+		}
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	history := func(token string) HistoryResponse {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s/history", deckID), nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var historyResponse HistoryResponse
+		err := json.NewDecoder(w.Body).Decode(&historyResponse)
+		require.NoError(t, err)
+		return historyResponse
+	}
+
+	historyResponse := history(token)
+	assert.Equal(t, deckID, historyResponse.DeckID)
+	assert.Equal(t, uint64(3), historyResponse.Version)
+	assert.True(t, historyResponse.Verified, "Replaying the history leads to the deck")
+
+	events := historyResponse.Events
+	require.Len(t, events, 5)
+	expectedEvents := []struct {
+		eventType string
+		actor     string
+	}{
+		{eventType: "created", actor: "owner"},
+		{eventType: "shuffled", actor: "owner"},
+		{eventType: "drawn", actor: "anonymous"},
+		{eventType: "returned", actor: "owner"},
+		{eventType: "inserted", actor: "owner"},
+	}
+	for i, expected := range expectedEvents {
+		assert.Equal(t, i+1, events[i].Sequence)
+		assert.Equal(t, expected.eventType, events[i].Type)
+		assert.Equal(t, expected.actor, events[i].Actor)
+		assert.False(t, events[i].Time.IsZero())
+	}
+	assert.Len(t, events[2].Cards, 2)
+	assert.Equal(t, []card.Card{{Rank: card.Ace, Suit: card.Spades}}, events[3].Cards)
+	assert.Equal(t, "top", events[4].Position)
+	assert.Equal(t, "uniform", events[1].Method)
+	assert.Equal(t, openOwnedDeck(t, router, deckID, token).Cards, events[4].Order, "The owner sees the order of the deck")
+
+	// Others see what happened, but not the order of the deck, nor which cards were inserted where.
+	events = history("").Events
+	require.Len(t, events, 5)
+	for _, event := range events {
+		assert.Empty(t, event.Order)
+	}
+	assert.Equal(t, "inserted", events[4].Type)
+	assert.Empty(t, events[4].Cards)
+	assert.Empty(t, events[4].Position)
+	assert.Len(t, events[2].Cards, 2, "Drawn cards are not secret")
+}
+
+func TestHistoryArchived(t *testing.T) {
+	store := deck.NewStore()
+	router := setup(WithRepository(store))
+	deckID, token := createTestDeckWithToken(router, "")
+
+	// The oldest events of a long history are archived by the store.
+	for i := 0; i < 1500; i++ {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?cards=AS", deckID), nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/return?cards=AS", deckID), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+	archived, err := store.ArchivedHistory(deckID, 0, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, archived)
+
+	// Paging through the history lists every event, from the creation of the deck.
+	var sequences []int
+	params := "?limit=400"
+	for pages := 1; ; pages++ {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s/history%s", deckID, params), nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var historyResponse HistoryResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&historyResponse))
+		for _, event := range historyResponse.Events {
+			assert.NotEqual(t, string(deck.EventCompacted), event.Type)
+			sequences = append(sequences, event.Sequence)
+		}
+		if historyResponse.NextAfter == 0 {
+			break
+		}
+		params = fmt.Sprintf("?limit=400&after=%d", historyResponse.NextAfter)
+	}
+
+	require.NotEmpty(t, sequences)
+	for i, sequence := range sequences {
+		require.Equal(t, i+1, sequence, "Every event is listed once, in order")
+	}
+	assert.Greater(t, len(sequences), 2000)
+}
+
+func TestHistoryInvalidRequests(t *testing.T) {
+	router := setup()
+
+	testCases := []struct {
+		name         string
+		deckID       string
+		params       string
+		expectedCode int
+	}{
+		{
+			name:         "invalid deck ID",
+			deckID:       "not-a-uuid",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "deck not found",
+			deckID:       uuid.NewString(),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "negative after parameter",
+			deckID:       uuid.NewString(),
+			params:       "?after=-1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid limit parameter",
+			deckID:       uuid.NewString(),
+			params:       "?limit=1001",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s/history%s", tc.deckID, tc.params), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code, "Expected status code to match")
+		})
+	}
+}
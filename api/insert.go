@@ -0,0 +1,88 @@
+package api
+
+import (
+	"deck-of-cards/card"
+	"deck-of-cards/deck"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// insertCardsHandler is a Gin route handler for inserting the cards listed in the "cards" query parameter into an
+// existing deck. Cards drawn from the deck are put back, and other cards are added to it, as long as the deck does not
+// end up with a card more times than it allows (once, or once per standard deck for a shoe).
+// The optional "position" query parameter tells where the cards are inserted: "top", "bottom" (the default),
+// "random" (each card at its own random position), or the index of the first inserted card from the top of the deck.
+// Only the owner of the deck (see ownerToken) can insert cards, as they would know where the cards sit in the deck.
+//
+// Example query parameters for putting a Joker back somewhere in the deck:
+// /deck/:deck_id/insert?cards=X1&position=random
+func (server *Server) insertCardsHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	queryCards, exists := c.GetQuery("cards")
+	if !exists {
+		respondWithError(c, invalidParameter("cards", "cards parameter must be provided."))
+		return
+	}
+	cards, err := card.FromStrings(strings.Split(queryCards, ","))
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	position, err := positionQuery(c)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	deckRetrieved, updated := server.updateDeck(c, deckID, func(d *deck.Deck) error {
+		if !isOwner(c, d) {
+			return forbidden("only the owner of the deck can insert cards in it.")
+		}
+		return d.Insert(cards, position)
+	})
+	if !updated {
+		return
+	}
+
+	jsonResponse := InsertCardsResponse{
+		DeckID:    deckRetrieved.ID,
+		Remaining: deckRetrieved.Remaining,
+		Version:   deckRetrieved.Version,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// positionQuery parses the "position" query parameter of insertCardsHandler.
+func positionQuery(c *gin.Context) (deck.Position, error) {
+	switch positionStr := c.DefaultQuery("position", "bottom"); positionStr {
+	case "top":
+		return deck.PositionTop, nil
+	case "bottom":
+		return deck.PositionBottom, nil
+	case "random":
+		return deck.PositionRandom, nil
+	default:
+		index, err := strconv.Atoi(positionStr)
+		if err != nil || index < 0 {
+			return 0, invalidParameter("position", "position parameter must be top, bottom, random, or a non-negative integer")
+		}
+		return deck.Position(index), nil
+	}
+}
+
+// InsertCardsResponse is a struct that represents the JSON response for the insertCardsHandler.
+type InsertCardsResponse struct {
+	DeckID    uuid.UUID `json:"deck_id"`
+	Remaining int       `json:"remaining"`
+	// Version is incremented each time the deck changes (see DrawCardsResponse).
+	Version uint64 `json:"version"`
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"deck-of-cards/card"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInsertCards(t *testing.T) {
+	testCases := []struct {
+		name          string
+		params        string
+		expectedCards string
+	}{
+		{
+			name:          "at the bottom by default",
+			params:        "?cards=X1",
+			expectedCards: "QH,4D,AC,X1",
+		},
+		{
+			name:          "on top",
+			params:        "?cards=X1,X2&position=top",
+			expectedCards: "X1,X2,QH,4D,AC",
+		},
+		{
+			name:          "at an index",
+			params:        "?cards=X1&position=2",
+			expectedCards: "QH,4D,X1,AC",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := setup()
+			deckID, token := createTestDeckWithToken(router, "?cards=QH,4D,AC")
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/insert%s", deckID, tc.params), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var insertResponse InsertCardsResponse
+			err := json.NewDecoder(w.Body).Decode(&insertResponse)
+			require.NoError(t, err)
+			expectedCards, _ := card.FromStrings(strings.Split(tc.expectedCards, ","))
+			assert.Equal(t, deckID, insertResponse.DeckID)
+			assert.Equal(t, len(expectedCards), insertResponse.Remaining)
+			assert.Equal(t, uint64(1), insertResponse.Version)
+
+			assert.Equal(t, expectedCards, openOwnedDeck(t, router, deckID, token).Cards)
+		})
+	}
+}
+
+func TestInsertDrawnCardAtRandom(t *testing.T) {
+	router := setup()
+	deckID, token := createTestDeckWithToken(router, "")
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?cards=AS", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// A drawn card can be put back anywhere in the deck.
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/insert?cards=AS&position=random", deckID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	openResponse := openOwnedDeck(t, router, deckID, token)
+	assert.Equal(t, 52, openResponse.Remaining)
+	assert.Contains(t, openResponse.Cards, card.Card{Rank: card.Ace, Suit: card.Spades})
+}
+
+func TestInsertCardsInvalidRequests(t *testing.T) {
+	router := setup()
+	deckID, deckToken := createTestDeckWithToken(router, "?cards=QH,4D,AC")
+
+	testCases := []struct {
+		name   string
+		deckID string
+		params string
+		// token is sent instead of the owner token of the deck, if it is not empty.
+		token        string
+		expectedCode int
+	}{
+		{
+			name:         "without cards parameter",
+			deckID:       deckID.String(),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid card code",
+			deckID:       deckID.String(),
+			params:       "?cards=ZZ",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "card already in the deck",
+			deckID:       deckID.String(),
+			params:       "?cards=QH",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "invalid position",
+			deckID:       deckID.String(),
+			params:       "?cards=AS&position=middle",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "position after the bottom of the deck",
+			deckID:       deckID.String(),
+			params:       "?cards=AS&position=4",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "not the owner",
+			deckID:       deckID.String(),
+			params:       "?cards=AS",
+			token:        "not-the-owner-token",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "deck not found",
+			deckID:       uuid.NewString(),
+			params:       "?cards=AS",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/insert%s", tc.deckID, tc.params), nil)
+			token := deckToken
+			if tc.token != "" {
+				token = tc.token
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code, "Expected status code to match")
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"deck-of-cards/deck"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultListLimit and maxListLimit are the default and maximum number of decks listed by listDecksHandler at once.
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// listDecksHandler is a Gin route handler for listing the decks of the store, from the oldest to the newest, so
+// decks whose ID was lost can be found again. As deck IDs give access to the decks, clients only list the decks owned
+// by their owner token (see ownerToken). The administrator of the server lists every deck.
+//
+// Optional query parameters filter the listed decks:
+// - "owner": decks of the given owner (see createDeckHandler).
+// - "shuffled": shuffled ("true") or not shuffled ("false") decks.
+// - "min_remaining" and "max_remaining": decks with at least (or at most) the given number of remaining cards.
+// - "created_after": decks created after the given time, formatted as RFC 3339 (e.g. 2023-05-01T12:00:00Z).
+//
+// At most "limit" decks are listed at once (50 by default). If there are more decks, the response holds a
+// "next_cursor", to be sent as the "cursor" query parameter to list the next ones.
+//
+// Example:
+// GET /decks?owner=table-1&min_remaining=10&limit=20
+func (server *Server) listDecksHandler(c *gin.Context) {
+	filter := deck.DeckFilter{Owner: c.Query("owner")}
+	if !server.isAdmin(c) {
+		filter.OwnerToken = ownerToken(c)
+		if filter.OwnerToken == "" {
+			respondWithError(c, forbidden("an owner token is required to list decks."))
+			return
+		}
+	}
+	if shuffledStr, exists := c.GetQuery("shuffled"); exists {
+		shuffled, err := strconv.ParseBool(shuffledStr)
+		if err != nil {
+			respondWithError(c, invalidParameter("shuffled", "shuffled parameter must be true or false"))
+			return
+		}
+		filter.Shuffled = &shuffled
+	}
+	var valid bool
+	if filter.MinRemaining, valid = optionalCountQuery(c, "min_remaining"); !valid {
+		return
+	}
+	if filter.MaxRemaining, valid = optionalCountQuery(c, "max_remaining"); !valid {
+		return
+	}
+	if createdAfterStr, exists := c.GetQuery("created_after"); exists {
+		createdAfter, err := time.Parse(time.RFC3339, createdAfterStr)
+		if err != nil {
+			respondWithError(c, invalidParameter("created_after", "created_after parameter must be a RFC 3339 time (e.g. 2023-05-01T12:00:00Z)"))
+			return
+		}
+		filter.CreatedAfter = createdAfter
+	}
+
+	page := deck.Page{Cursor: c.Query("cursor"), Limit: defaultListLimit}
+	if limitStr, exists := c.GetQuery("limit"); exists {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxListLimit {
+			respondWithError(c, invalidParameter("limit", "limit parameter must be an integer between 1 and 500"))
+			return
+		}
+		page.Limit = limit
+	}
+
+	decks, nextCursor, err := server.store.List(filter, page)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	jsonResponse := ListDecksResponse{
+		Decks:      make([]DeckSummary, len(decks)),
+		NextCursor: nextCursor,
+	}
+	for i, d := range decks {
+		jsonResponse.Decks[i] = DeckSummary{
+			DeckID:    d.ID,
+			Shuffled:  d.Shuffled,
+			Remaining: d.Remaining,
+			DeckCount: d.DeckCount,
+			Owner:     d.Owner,
+			Metadata:  d.Metadata,
+			CreatedAt: d.CreatedAt,
+			ExpiresAt: expiresAt(d),
+		}
+		if seed, seeded := d.Seed(); seeded {
+			jsonResponse.Decks[i].Seed = &seed
+		}
+		if d.Proof != nil {
+			jsonResponse.Decks[i].Commitment = d.Proof.Commitment
+		}
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// optionalCountQuery parses the optional query parameter param as a non-negative integer. It returns nil if the
+// parameter is not provided. If the parameter is not valid, an error response is written, and false is returned.
+func optionalCountQuery(c *gin.Context, param string) (*int, bool) {
+	valueStr, exists := c.GetQuery(param)
+	if !exists {
+		return nil, true
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 0 {
+		respondWithError(c, invalidParameter(param, param+" parameter must be a non-negative integer"))
+		return nil, false
+	}
+	return &value, true
+}
+
+// ListDecksResponse is a struct that represents the JSON response for the listDecksHandler.
+type ListDecksResponse struct {
+	Decks []DeckSummary `json:"decks"`
+	// NextCursor is the cursor of the next decks. It is only present if there are more decks.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// DeckSummary describes a deck listed by the listDecksHandler, with the fields of CreateDeckResponse (except for
+// the owner token) and the creation time of the deck.
+type DeckSummary struct {
+	DeckID     uuid.UUID         `json:"deck_id"`
+	Shuffled   bool              `json:"shuffled"`
+	Remaining  int               `json:"remaining"`
+	DeckCount  int               `json:"deck_count"`
+	Seed       *int64            `json:"seed,omitempty"`
+	Commitment string            `json:"commitment,omitempty"`
+	Owner      string            `json:"owner,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	ExpiresAt  *time.Time        `json:"expires_at,omitempty"`
+}
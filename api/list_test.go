@@ -0,0 +1,215 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// listTestDecks lists the decks with the given query parameters, as the administrator.
+func listTestDecks(t *testing.T, handler http.Handler, params string) ListDecksResponse {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/decks"+params, nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var listResponse ListDecksResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&listResponse))
+	return listResponse
+}
+
+func TestListDecks(t *testing.T) {
+	router := setup(WithAdminToken("admin-token"))
+
+	aliceDeck := createTestDeck(router, "?owner=alice&seed=42")
+	aliceHand := createTestDeck(router, "?owner=alice&cards=AS,KD")
+	bobDeck := createTestDeck(router, "?owner=bob")
+
+	testCases := []struct {
+		name     string
+		params   string
+		expected []uuid.UUID
+	}{
+		{
+			name:     "all decks",
+			params:   "",
+			expected: []uuid.UUID{aliceDeck, aliceHand, bobDeck},
+		},
+		{
+			name:     "by owner",
+			params:   "?owner=alice",
+			expected: []uuid.UUID{aliceDeck, aliceHand},
+		},
+		{
+			name:     "shuffled",
+			params:   "?shuffled=true",
+			expected: []uuid.UUID{aliceDeck},
+		},
+		{
+			name:     "not shuffled",
+			params:   "?shuffled=false",
+			expected: []uuid.UUID{aliceHand, bobDeck},
+		},
+		{
+			name:     "remaining range",
+			params:   "?min_remaining=1&max_remaining=10",
+			expected: []uuid.UUID{aliceHand},
+		},
+		{
+			name:     "created after",
+			params:   "?created_after=2999-01-01T00:00:00Z",
+			expected: []uuid.UUID{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			listResponse := listTestDecks(t, router, tc.params)
+
+			ids := []uuid.UUID{}
+			for _, summary := range listResponse.Decks {
+				ids = append(ids, summary.DeckID)
+			}
+			assert.ElementsMatch(t, tc.expected, ids)
+			assert.Empty(t, listResponse.NextCursor)
+		})
+	}
+
+	// Summaries hold the fields of the create response, except for the owner token.
+	listResponse := listTestDecks(t, router, "?owner=alice&shuffled=true")
+	require.Len(t, listResponse.Decks, 1)
+	summary := listResponse.Decks[0]
+	assert.Equal(t, "alice", summary.Owner)
+	assert.Equal(t, 52, summary.Remaining)
+	assert.Equal(t, 1, summary.DeckCount)
+	require.NotNil(t, summary.Seed)
+	assert.Equal(t, int64(42), *summary.Seed)
+	assert.False(t, summary.CreatedAt.IsZero())
+}
+
+func TestListOwnDecks(t *testing.T) {
+	router := setup()
+
+	firstDeck, token := createTestDeckWithToken(router, "")
+	require.NotEmpty(t, token)
+	createTestDeck(router, "")
+
+	// A deck created with an owner token is owned by that token.
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/deck/new", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var createResponse CreateDeckResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&createResponse))
+	assert.Equal(t, token, createResponse.OwnerToken)
+	secondDeck := createResponse.DeckID
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/decks", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var listResponse ListDecksResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&listResponse))
+	var ids []uuid.UUID
+	for _, summary := range listResponse.Decks {
+		ids = append(ids, summary.DeckID)
+	}
+	assert.ElementsMatch(t, []uuid.UUID{firstDeck, secondDeck}, ids, "Only the decks of the owner token are listed")
+}
+
+func TestListDecksByPage(t *testing.T) {
+	router := setup(WithAdminToken("admin-token"))
+
+	var created []uuid.UUID
+	for i := 0; i < 5; i++ {
+		created = append(created, createTestDeck(router, ""))
+	}
+
+	var listed []uuid.UUID
+	params := "?limit=2"
+	for pages := 1; ; pages++ {
+		listResponse := listTestDecks(t, router, params)
+		for _, summary := range listResponse.Decks {
+			listed = append(listed, summary.DeckID)
+		}
+		if listResponse.NextCursor == "" {
+			assert.Equal(t, 3, pages)
+			break
+		}
+		params = "?limit=2&cursor=" + listResponse.NextCursor
+	}
+	assert.ElementsMatch(t, created, listed)
+}
+
+func TestListDecksErrors(t *testing.T) {
+	router := setup(WithAdminToken("admin-token"))
+
+	testCases := []struct {
+		name         string
+		params       string
+		token        string
+		expectedCode int
+	}{
+		{
+			name:         "no token",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "token owning no deck",
+			token:        "not-a-token",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "invalid shuffled parameter",
+			params:       "?shuffled=maybe",
+			token:        "admin-token",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "negative min_remaining parameter",
+			params:       "?min_remaining=-1",
+			token:        "admin-token",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid created_after parameter",
+			params:       "?created_after=yesterday",
+			token:        "admin-token",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid limit parameter",
+			params:       "?limit=0",
+			token:        "admin-token",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid cursor parameter",
+			params:       "?cursor=invalid",
+			token:        "admin-token",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/decks"+tc.params, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
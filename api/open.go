@@ -5,20 +5,30 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"net/http"
+	"time"
 )
 
 // openDeckHandler is a Gin route handler for retrieving an existing deck by its ID.
 // The deck ID is provided as a URL parameter. If the deck is found, the deck information is returned as JSON.
+//
+// The remaining cards are hidden, as anybody knowing them in draw-order would know every future card. They are only
+// listed in draw-order to the owner of the deck (see ownerToken). With "unordered=true", the remaining cards are
+// listed to anyone, sorted in the order of a new deck, which tells which cards are left but not when they will come.
 func (server *Server) openDeckHandler(c *gin.Context) {
 	deckID, err := uuid.Parse(c.Param("deck_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "deck ID is not valid."})
+		respondWithError(c, errInvalidDeckID)
 		return
 	}
 
-	deckRetrieved, notFound := server.store.Get(deckID)
-	if notFound != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "deck not found. Are you sure deck_id is correct?"})
+	unordered, err := boolQuery(c, "unordered")
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	deckRetrieved, found := server.getDeck(c, deckID)
+	if !found {
 		return
 	}
 
@@ -26,15 +36,38 @@ func (server *Server) openDeckHandler(c *gin.Context) {
 		DeckID:    deckRetrieved.ID,
 		Shuffled:  deckRetrieved.Shuffled,
 		Remaining: deckRetrieved.Remaining,
-		Cards:     deckRetrieved.Cards,
+		Version:   deckRetrieved.Version,
+		Metadata:  deckRetrieved.Metadata,
+		ExpiresAt: expiresAt(deckRetrieved),
+	}
+	switch {
+	case unordered:
+		jsonResponse.Cards = deckRetrieved.UnorderedCards()
+	case isOwner(c, deckRetrieved):
+		jsonResponse.Cards = deckRetrieved.Cards
+	}
+	if len(deckRetrieved.Piles) > 0 {
+		jsonResponse.Piles = make(map[string]int, len(deckRetrieved.Piles))
+		for name, pile := range deckRetrieved.Piles {
+			jsonResponse.Piles[name] = pile.Remaining()
+		}
 	}
 	c.JSON(http.StatusOK, jsonResponse)
 }
 
 // OpenDeckResponse is a struct that represents the JSON response for the openDeckHandler.
 type OpenDeckResponse struct {
-	DeckID    uuid.UUID   `json:"deck_id"`
-	Shuffled  bool        `json:"shuffled"`
-	Remaining int         `json:"remaining"`
-	Cards     []card.Card `json:"cards"`
+	DeckID    uuid.UUID `json:"deck_id"`
+	Shuffled  bool      `json:"shuffled"`
+	Remaining int       `json:"remaining"`
+	// Version is incremented each time the deck changes (see DrawCardsResponse).
+	Version uint64 `json:"version"`
+	// Cards holds the remaining cards, if the caller is allowed to see them (see openDeckHandler).
+	Cards []card.Card `json:"cards,omitempty"`
+	// Piles holds the number of cards in each pile of the deck, by pile name.
+	Piles map[string]int `json:"piles,omitempty"`
+	// Metadata is the data attached to the deck on creation, if any.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// ExpiresAt is when the deck expires if it is not used until then. It is only present if the deck expires.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
@@ -2,13 +2,17 @@ package api
 
 import (
 	"deck-of-cards/card"
+	"deck-of-cards/deck"
 	"encoding/json"
 	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestOpenDeck(t *testing.T) {
@@ -70,9 +74,10 @@ func TestOpenPartialDeck(t *testing.T) {
 	// Keep track of the deck's ID.
 	deckID := createResponse.DeckID
 
-	// 2. Open the (same) deck through Open endpoint.
+	// 2. Open the (same) deck through Open endpoint, as the owner of the deck.
 	w = httptest.NewRecorder()
 	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s", deckID), nil)
+	req.Header.Set("Authorization", "Bearer "+createResponse.OwnerToken)
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, w.Code, http.StatusOK)
@@ -84,9 +89,161 @@ func TestOpenPartialDeck(t *testing.T) {
 	assert.Equal(t, deckID, openResponse.DeckID, "Deck ID does not change after it is created.")
 	assert.False(t, openResponse.Shuffled, "Deck Shuffled does not change after it is created.")
 	assert.Equal(t, len(expectedCards), openResponse.Remaining, "If we do not Draw from the deck, all cards still remain.")
+	require.Len(t, openResponse.Cards, len(expectedCards), "The owner of the deck can see the cards.")
 
 	// The cards are in the correct order (the order we specified in the request).
 	for i, c := range expectedCards {
 		assert.Equal(t, c, openResponse.Cards[i])
 	}
 }
+
+func TestOpenDeckHidesCards(t *testing.T) {
+	router := setup()
+
+	deckID, token := createTestDeckWithToken(router, "?cards=KH,AS,2C,KD,AC")
+	require.NotEmpty(t, token, "An owner token is issued on creation")
+
+	testCases := []struct {
+		name          string
+		params        string
+		authorization string
+		expectedCards []string
+	}{
+		{
+			name:          "no owner token",
+			expectedCards: nil,
+		},
+		{
+			name:          "wrong owner token",
+			authorization: "Bearer not-the-token",
+			expectedCards: nil,
+		},
+		{
+			name:          "owner token without Bearer scheme",
+			authorization: token,
+			expectedCards: nil,
+		},
+		{
+			name:          "owner",
+			authorization: "Bearer " + token,
+			expectedCards: []string{"KH", "AS", "2C", "KD", "AC"},
+		},
+		{
+			name:          "unordered cards",
+			params:        "?unordered=true",
+			expectedCards: []string{"AS", "KD", "AC", "2C", "KH"},
+		},
+		{
+			name:          "unordered cards, as any boolean",
+			params:        "?unordered=1",
+			expectedCards: []string{"AS", "KD", "AC", "2C", "KH"},
+		},
+		{
+			name:          "unordered cards for the owner",
+			params:        "?unordered=true",
+			authorization: "Bearer " + token,
+			expectedCards: []string{"AS", "KD", "AC", "2C", "KH"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s%s", deckID, tc.params), nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var openResponse OpenDeckResponse
+			err := json.NewDecoder(w.Body).Decode(&openResponse)
+			require.NoError(t, err)
+
+			var codes []string
+			for _, c := range openResponse.Cards {
+				codes = append(codes, c.String())
+			}
+			assert.Equal(t, tc.expectedCards, codes)
+			assert.Equal(t, 5, openResponse.Remaining, "Everybody can see how many cards remain")
+		})
+	}
+}
+
+func TestOpenDeckInvalidUnordered(t *testing.T) {
+	router := setup()
+	deckID := createTestDeck(router, "")
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s?unordered=yes", deckID), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "Booleans which can not be parsed are rejected")
+}
+
+func TestOpenDeckExpiresAt(t *testing.T) {
+	store := deck.NewStoreWithConfig(deck.StoreConfig{IdleTTL: time.Hour})
+	defer store.Close()
+	router := setup(WithRepository(store))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/deck/new", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var createResponse CreateDeckResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&createResponse))
+	require.NotNil(t, createResponse.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *createResponse.ExpiresAt, time.Minute)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s", createResponse.DeckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var openResponse OpenDeckResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&openResponse))
+	require.NotNil(t, openResponse.ExpiresAt)
+	assert.False(t, openResponse.ExpiresAt.Before(*createResponse.ExpiresAt), "Opening the deck keeps it alive")
+
+	// Decks of the default store never expire.
+	router = setup()
+	deckID := createTestDeck(router, "")
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s", deckID), nil)
+	router.ServeHTTP(w, req)
+	assert.NotContains(t, w.Body.String(), "expires_at")
+}
+
+func TestOpenExpiredDeck(t *testing.T) {
+	store := deck.NewStoreWithConfig(deck.StoreConfig{IdleTTL: time.Millisecond})
+	defer store.Close()
+	router := setup(WithRepository(store))
+
+	deckID := createTestDeck(router, "")
+	time.Sleep(10 * time.Millisecond)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s", deckID), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.Contains(t, w.Body.String(), "deck expired")
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=1", deckID), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusGone, w.Code)
+}
+
+// openOwnedDeck opens the deck as its owner, so the response holds the remaining cards in draw-order.
+func openOwnedDeck(t *testing.T, router *gin.Engine, deckID uuid.UUID, token string) OpenDeckResponse {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s", deckID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var openResponse OpenDeckResponse
+	err := json.NewDecoder(w.Body).Decode(&openResponse)
+	require.NoError(t, err)
+	return openResponse
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"deck-of-cards/card"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+)
+
+// peekHandler is a Gin route handler for looking at the top cards of an existing deck, without drawing them.
+// The optional "count" query parameter is the number of cards to look at (1 by default).
+// Only the owner of the deck (see ownerToken) can peek at its cards, as it reveals the order of the deck.
+//
+// Example query parameters for looking at the top three cards:
+// /deck/:deck_id/peek?count=3
+func (server *Server) peekHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	count, err := strconv.Atoi(c.DefaultQuery("count", "1"))
+	if err != nil || count <= 0 {
+		respondWithError(c, invalidParameter("count", "count parameter must be a positive integer"))
+		return
+	}
+
+	deckRetrieved, found := server.getDeck(c, deckID)
+	if !found {
+		return
+	}
+
+	if !isOwner(c, deckRetrieved) {
+		respondWithError(c, forbidden("only the owner of the deck can peek at its cards."))
+		return
+	}
+
+	peekedCards, err := deckRetrieved.Peek(count)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	jsonResponse := PeekResponse{
+		DeckID:    deckRetrieved.ID,
+		Cards:     peekedCards,
+		Remaining: deckRetrieved.Remaining,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// PeekResponse is a struct that represents the JSON response for the peekHandler.
+type PeekResponse struct {
+	DeckID uuid.UUID `json:"deck_id"`
+	// Cards holds the top cards of the deck, in draw-order. They are still in the deck.
+	Cards     []card.Card `json:"cards"`
+	Remaining int         `json:"remaining"`
+}
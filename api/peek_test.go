@@ -0,0 +1,108 @@
+package api
+
+import (
+	"deck-of-cards/card"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPeek(t *testing.T) {
+	router := setup()
+
+	deckID, token := createTestDeckWithToken(router, "?cards=QH,4D,AC")
+
+	peek := func(params string) PeekResponse {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s/peek%s", deckID, params), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var peekResponse PeekResponse
+		err := json.NewDecoder(w.Body).Decode(&peekResponse)
+		require.NoError(t, err)
+		return peekResponse
+	}
+
+	peekResponse := peek("")
+	assert.Equal(t, deckID, peekResponse.DeckID)
+	assert.Equal(t, []card.Card{{Rank: card.Queen, Suit: card.Hearts}}, peekResponse.Cards, "The top card is peeked by default")
+	assert.Equal(t, 3, peekResponse.Remaining)
+
+	peekResponse = peek("?count=2")
+	assert.Equal(t, []card.Card{{Rank: card.Queen, Suit: card.Hearts}, {Rank: card.Four, Suit: card.Diamonds}}, peekResponse.Cards)
+	assert.Equal(t, 3, peekResponse.Remaining, "Peeking does not draw the cards")
+
+	// The peeked cards are the next ones to be drawn.
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=2", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var drawResponse DrawCardsResponse
+	err := json.NewDecoder(w.Body).Decode(&drawResponse)
+	require.NoError(t, err)
+	assert.Equal(t, peekResponse.Cards, drawResponse.Cards)
+}
+
+func TestPeekInvalidRequests(t *testing.T) {
+	router := setup()
+
+	deckID, token := createTestDeckWithToken(router, "?cards=QH,4D,AC")
+
+	testCases := []struct {
+		name         string
+		target       string
+		token        string
+		expectedCode int
+	}{
+		{
+			name:         "without owner token",
+			target:       fmt.Sprintf("/deck/%s/peek", deckID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "with another deck's token",
+			target:       fmt.Sprintf("/deck/%s/peek", deckID),
+			token:        "not-the-owner-token",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "more cards than remaining",
+			target:       fmt.Sprintf("/deck/%s/peek?count=4", deckID),
+			token:        token,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "invalid count",
+			target:       fmt.Sprintf("/deck/%s/peek?count=zero", deckID),
+			token:        token,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown deck",
+			target:       fmt.Sprintf("/deck/%s/peek", uuid.NewString()),
+			token:        token,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code, "Expected status code to match")
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"deck-of-cards/card"
+	"deck-of-cards/deck"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// addToPileHandler is a Gin route handler for moving drawn cards into a named pile of an existing deck.
+// The cards are provided as a comma-separated list of codes in the "cards" query parameter.
+// The pile is created if it does not exist yet.
+//
+// Example:
+// /deck/:deck_id/pile/discard/add?cards=AS,2S
+func (server *Server) addToPileHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	queryCards, exists := c.GetQuery("cards")
+	if !exists {
+		respondWithError(c, invalidParameter("cards", "cards parameter must be provided."))
+		return
+	}
+	cards, err := card.FromStrings(strings.Split(queryCards, ","))
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	pileName := c.Param("pile_name")
+	deckRetrieved, updated := server.updateDeck(c, deckID, func(d *deck.Deck) error {
+		return d.AddToPile(pileName, cards)
+	})
+	if !updated {
+		return
+	}
+
+	pile, _ := deckRetrieved.Pile(pileName)
+	jsonResponse := PileResponse{
+		DeckID:    deckRetrieved.ID,
+		Pile:      pile.Name,
+		Remaining: pile.Remaining(),
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// listPileHandler is a Gin route handler for listing the cards in a named pile of an existing deck.
+func (server *Server) listPileHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	deckRetrieved, found := server.getDeck(c, deckID)
+	if !found {
+		return
+	}
+
+	pile, err := deckRetrieved.Pile(c.Param("pile_name"))
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	jsonResponse := PileResponse{
+		DeckID:    deckRetrieved.ID,
+		Pile:      pile.Name,
+		Remaining: pile.Remaining(),
+		Cards:     pile.Cards,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// drawFromPileHandler is a Gin route handler for drawing a specified number of cards from the top of a named pile.
+// The card count is provided as the "count" query parameter. The drawn cards are returned as JSON.
+func (server *Server) drawFromPileHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	countStr, exists := c.GetQuery("count")
+	if !exists {
+		respondWithError(c, invalidParameter("count", "count parameter must be provided."))
+		return
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		respondWithError(c, invalidParameter("count", "count parameter must be a positive integer"))
+		return
+	}
+
+	var drawnCards []card.Card
+	_, updated := server.updateDeck(c, deckID, func(d *deck.Deck) error {
+		var err error
+		drawnCards, err = d.DrawFromPile(c.Param("pile_name"), count)
+		return err
+	})
+	if !updated {
+		return
+	}
+
+	jsonResponse := DrawCardsResponse{
+		Cards: drawnCards,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// shufflePileHandler is a Gin route handler for shuffling a named pile of an existing deck.
+func (server *Server) shufflePileHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	pileName := c.Param("pile_name")
+	deckRetrieved, updated := server.updateDeck(c, deckID, func(d *deck.Deck) error {
+		return d.ShufflePile(pileName)
+	})
+	if !updated {
+		return
+	}
+
+	pile, _ := deckRetrieved.Pile(pileName)
+	jsonResponse := PileResponse{
+		DeckID:    deckRetrieved.ID,
+		Pile:      pile.Name,
+		Remaining: pile.Remaining(),
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// PileResponse is a struct that represents the JSON response for the pile handlers.
+// Cards are only listed by listPileHandler.
+type PileResponse struct {
+	DeckID    uuid.UUID   `json:"deck_id"`
+	Pile      string      `json:"pile"`
+	Remaining int         `json:"remaining"`
+	Cards     []card.Card `json:"cards,omitempty"`
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDrawIntoPileAndList(t *testing.T) {
+	router := setup()
+
+	deckID := createTestDeck(router, "?cards=QH,4D,AC,2C,KH")
+
+	// Draw two cards directly into alice's hand.
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=2&pile=alice", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// List alice's hand.
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s/pile/alice", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var pileResponse PileResponse
+	err := json.NewDecoder(w.Body).Decode(&pileResponse)
+	require.NoError(t, err)
+
+	assert.Equal(t, deckID, pileResponse.DeckID)
+	assert.Equal(t, "alice", pileResponse.Pile)
+	assert.Equal(t, 2, pileResponse.Remaining)
+	require.Len(t, pileResponse.Cards, 2)
+	assert.Equal(t, "4D", pileResponse.Cards[0].String(), "The last drawn card is on top of the pile")
+	assert.Equal(t, "QH", pileResponse.Cards[1].String())
+
+	// The deck knows about its piles.
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var openResponse OpenDeckResponse
+	err = json.NewDecoder(w.Body).Decode(&openResponse)
+	require.NoError(t, err)
+	assert.Equal(t, 3, openResponse.Remaining)
+	assert.Equal(t, map[string]int{"alice": 2}, openResponse.Piles)
+}
+
+func TestAddToPileAndDrawFromPile(t *testing.T) {
+	router := setup()
+
+	deckID := createTestDeck(router, "?cards=AS,2S,3S")
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=2", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/pile/discard/add?cards=AS,2S", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var pileResponse PileResponse
+	err := json.NewDecoder(w.Body).Decode(&pileResponse)
+	require.NoError(t, err)
+	assert.Equal(t, 2, pileResponse.Remaining)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/pile/discard/shuffle", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/pile/discard/draw?count=2", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var drawResponse DrawCardsResponse
+	err = json.NewDecoder(w.Body).Decode(&drawResponse)
+	require.NoError(t, err)
+	assert.Len(t, drawResponse.Cards, 2)
+}
+
+func TestPileHandlersInvalidRequests(t *testing.T) {
+	router := setup()
+
+	validID := createTestDeck(router, "?cards=AS,2S,3S")
+
+	testCases := []struct {
+		name         string
+		method       string
+		target       string
+		expectedCode int
+	}{
+		{
+			name:         "add card that was not drawn",
+			method:       http.MethodPost,
+			target:       fmt.Sprintf("/deck/%s/pile/discard/add?cards=AS", validID),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "add invalid card code",
+			method:       http.MethodPost,
+			target:       fmt.Sprintf("/deck/%s/pile/discard/add?cards=ZZ", validID),
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "add without cards parameter",
+			method:       http.MethodPost,
+			target:       fmt.Sprintf("/deck/%s/pile/discard/add", validID),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "list unknown pile",
+			method:       http.MethodGet,
+			target:       fmt.Sprintf("/deck/%s/pile/unknown", validID),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "draw from unknown pile",
+			method:       http.MethodPost,
+			target:       fmt.Sprintf("/deck/%s/pile/unknown/draw?count=1", validID),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "draw from pile with invalid count",
+			method:       http.MethodPost,
+			target:       fmt.Sprintf("/deck/%s/pile/unknown/draw?count=zero", validID),
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "shuffle unknown pile",
+			method:       http.MethodPost,
+			target:       fmt.Sprintf("/deck/%s/pile/unknown/shuffle", validID),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "list pile of unknown deck",
+			method:       http.MethodGet,
+			target:       fmt.Sprintf("/deck/%s/pile/discard", uuid.NewString()),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "list pile of invalid deck ID",
+			method:       http.MethodGet,
+			target:       "/deck/invalid-deck-id/pile/discard",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code, "Expected status code to match")
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"deck-of-cards/card"
+	"deck-of-cards/deck"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// proofHandler is a Gin route handler for revealing the proof of a deck's order (commit-reveal).
+// The proof is only available for decks shuffled with a commitment, once the deck is exhausted or closed.
+// With the proof, anyone can verify that the order published as a commitment on creation was not changed.
+func (server *Server) proofHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	deckRetrieved, found := server.getDeck(c, deckID)
+	if !found {
+		return
+	}
+
+	proof, err := deckRetrieved.RevealProof()
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	jsonResponse := ProofResponse{
+		DeckID:       deckRetrieved.ID,
+		Commitment:   proof.Commitment,
+		Salt:         proof.Salt,
+		ServerSeed:   proof.ServerSeed,
+		ClientSeed:   proof.ClientSeed,
+		InitialCards: proof.InitialCards,
+		Order:        proof.Order,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// closeDeckHandler is a Gin route handler for closing an existing deck. No cards can be drawn from a closed deck,
+// and its proof can be revealed. Only the owner of the deck (see ownerToken) can close it, so players can not force
+// the proof (and the order of the remaining cards) out.
+func (server *Server) closeDeckHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	deckRetrieved, updated := server.updateDeck(c, deckID, func(d *deck.Deck) error {
+		if !isOwner(c, d) {
+			return forbidden("only the owner of the deck can close it.")
+		}
+
+		d.Close()
+		return nil
+	})
+	if !updated {
+		return
+	}
+
+	jsonResponse := CloseDeckResponse{
+		DeckID:    deckRetrieved.ID,
+		Shuffled:  deckRetrieved.Shuffled,
+		Remaining: deckRetrieved.Remaining,
+		Closed:    deckRetrieved.Closed,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// ProofResponse is a struct that represents the JSON response for the proofHandler.
+// The commitment is the hex encoded SHA-256 of "<salt>:<codes of order, separated by commas>", and order is the
+// result of shuffling initial_cards with the server and client seeds.
+type ProofResponse struct {
+	DeckID       uuid.UUID   `json:"deck_id"`
+	Commitment   string      `json:"commitment"`
+	Salt         string      `json:"salt"`
+	ServerSeed   string      `json:"server_seed"`
+	ClientSeed   string      `json:"client_seed"`
+	InitialCards []card.Card `json:"initial_cards"`
+	Order        []card.Card `json:"order"`
+}
+
+// CloseDeckResponse is a struct that represents the JSON response for the closeDeckHandler.
+type CloseDeckResponse struct {
+	DeckID    uuid.UUID `json:"deck_id"`
+	Shuffled  bool      `json:"shuffled"`
+	Remaining int       `json:"remaining"`
+	Closed    bool      `json:"closed"`
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"deck-of-cards/deck"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProofOfExhaustedDeck(t *testing.T) {
+	router := setup()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/deck/new?cards=AS,KD,AC,2C,KH&client_seed=lucky", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var createResponse CreateDeckResponse
+	err := json.NewDecoder(w.Body).Decode(&createResponse)
+	require.NoError(t, err)
+	assert.True(t, createResponse.Shuffled, "A client seed implies shuffling")
+	require.NotEmpty(t, createResponse.Commitment, "The commitment is published on creation")
+	deckID := createResponse.DeckID
+
+	// The proof is not available while there are cards to draw.
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s/proof", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=5", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var drawResponse DrawCardsResponse
+	err = json.NewDecoder(w.Body).Decode(&drawResponse)
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s/proof", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var proofResponse ProofResponse
+	err = json.NewDecoder(w.Body).Decode(&proofResponse)
+	require.NoError(t, err)
+
+	assert.Equal(t, createResponse.Commitment, proofResponse.Commitment)
+	assert.Equal(t, "lucky", proofResponse.ClientSeed)
+	assert.Equal(t, drawResponse.Cards, proofResponse.Order, "Cards were drawn in the committed order")
+
+	proof := deck.Proof{
+		Commitment:   proofResponse.Commitment,
+		Salt:         proofResponse.Salt,
+		ServerSeed:   proofResponse.ServerSeed,
+		ClientSeed:   proofResponse.ClientSeed,
+		InitialCards: proofResponse.InitialCards,
+		Order:        proofResponse.Order,
+	}
+	assert.NoError(t, proof.Verify(), "Anyone can verify the revealed proof")
+}
+
+func TestProofOfClosedDeck(t *testing.T) {
+	router := setup()
+
+	deckID, token := createTestDeckWithToken(router, "?shuffled=true")
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/close", deckID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var closeResponse CloseDeckResponse
+	err := json.NewDecoder(w.Body).Decode(&closeResponse)
+	require.NoError(t, err)
+	assert.True(t, closeResponse.Closed)
+	assert.Equal(t, 52, closeResponse.Remaining)
+
+	// No cards can be drawn from a closed deck.
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=1", deckID), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s/proof", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var proofResponse ProofResponse
+	err = json.NewDecoder(w.Body).Decode(&proofResponse)
+	require.NoError(t, err)
+	assert.Len(t, proofResponse.Order, 52)
+}
+
+func TestProofInvalidRequests(t *testing.T) {
+	router := setup()
+
+	// Seeded decks are reproducible, and are not shuffled with a commitment.
+	seededID := createTestDeck(router, "?cards=AS&seed=1")
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=1", seededID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// A reshuffled deck is not drawn in the committed order anymore.
+	reshuffledID, token := createTestDeckWithToken(router, "?cards=AS,KD,QH&shuffled=true")
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/shuffle?method=cut&position=1", reshuffledID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=3", reshuffledID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	committedID := createTestDeck(router, "?shuffled=true")
+
+	testCases := []struct {
+		name         string
+		method       string
+		target       string
+		expectedCode int
+	}{
+		{
+			name:         "proof of a deck without commitment",
+			method:       http.MethodGet,
+			target:       fmt.Sprintf("/deck/%s/proof", seededID),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "proof of a reordered deck",
+			method:       http.MethodGet,
+			target:       fmt.Sprintf("/deck/%s/proof", reshuffledID),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "close without owner token",
+			method:       http.MethodPost,
+			target:       fmt.Sprintf("/deck/%s/close", committedID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "proof of unknown deck",
+			method:       http.MethodGet,
+			target:       fmt.Sprintf("/deck/%s/proof", uuid.NewString()),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "close unknown deck",
+			method:       http.MethodPost,
+			target:       fmt.Sprintf("/deck/%s/close", uuid.NewString()),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "seed and client seed together",
+			method:       http.MethodPost,
+			target:       "/deck/new?seed=1&client_seed=abc",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code, "Expected status code to match")
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"deck-of-cards/card"
+	"deck-of-cards/deck"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strings"
+)
+
+// returnCardsHandler is a Gin route handler for putting cards back at the bottom of an existing deck.
+// By default, every drawn card (including the cards in the deck's piles) is returned. The optional "cards" query
+// parameter returns only the listed cards, which must have been drawn from this deck.
+// Only the owner of the deck (see ownerToken) can return cards, as returning every card takes back the hands.
+//
+// Example query parameters for returning two cards:
+// /deck/:deck_id/return?cards=AS,KD
+func (server *Server) returnCardsHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	var cards []card.Card
+	queryCards, returnSome := c.GetQuery("cards")
+	if returnSome {
+		cards, err = card.FromStrings(strings.Split(queryCards, ","))
+		if err != nil {
+			respondWithError(c, err)
+			return
+		}
+	}
+
+	deckRetrieved, updated := server.updateDeck(c, deckID, func(d *deck.Deck) error {
+		if !isOwner(c, d) {
+			return forbidden("only the owner of the deck can return cards to it.")
+		}
+		if returnSome {
+			return d.Return(cards)
+		}
+		d.ReturnAll()
+		return nil
+	})
+	if !updated {
+		return
+	}
+
+	jsonResponse := ReturnCardsResponse{
+		DeckID:    deckRetrieved.ID,
+		Shuffled:  deckRetrieved.Shuffled,
+		Remaining: deckRetrieved.Remaining,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// ReturnCardsResponse is a struct that represents the JSON response for the returnCardsHandler.
+type ReturnCardsResponse struct {
+	DeckID    uuid.UUID `json:"deck_id"`
+	Shuffled  bool      `json:"shuffled"`
+	Remaining int       `json:"remaining"`
+}
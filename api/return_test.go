@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReturnCards(t *testing.T) {
+	testCases := []struct {
+		name              string
+		params            string
+		expectedRemaining int
+	}{
+		{
+			name:              "return all drawn cards",
+			params:            "",
+			expectedRemaining: 5,
+		},
+		{
+			name:              "return specific cards",
+			params:            "?cards=QH,AC",
+			expectedRemaining: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := setup()
+			deckID, token := createTestDeckWithToken(router, "?cards=QH,4D,AC,2C,KH")
+
+			// Draw three cards, one of them into a pile.
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=2", deckID), nil)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=1&pile=discard", deckID), nil)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/return%s", deckID, tc.params), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var returnResponse ReturnCardsResponse
+			err := json.NewDecoder(w.Body).Decode(&returnResponse)
+			require.NoError(t, err)
+			assert.Equal(t, deckID, returnResponse.DeckID, "Returning cards keeps the deck ID")
+			assert.Equal(t, tc.expectedRemaining, returnResponse.Remaining)
+		})
+	}
+}
+
+func TestReturnCardsInvalidRequests(t *testing.T) {
+	router := setup()
+
+	validID, deckToken := createTestDeckWithToken(router, "?cards=QH,4D,AC")
+
+	testCases := []struct {
+		name   string
+		deckID string
+		params string
+		// token is sent instead of the owner token of the deck, if it is not empty.
+		token        string
+		expectedCode int
+	}{
+		{
+			name:         "invalid deck ID",
+			deckID:       "invalid-deck-id",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "deck not found",
+			deckID:       uuid.NewString(),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "invalid card code",
+			deckID:       validID.String(),
+			params:       "?cards=ZZ",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "card that was not drawn",
+			deckID:       validID.String(),
+			params:       "?cards=QH",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "card from another deck",
+			deckID:       validID.String(),
+			params:       "?cards=9S",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "not the owner",
+			deckID:       validID.String(),
+			token:        "not-the-owner-token",
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/return%s", tc.deckID, tc.params), nil)
+			token := deckToken
+			if tc.token != "" {
+				token = tc.token
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code, "Expected status code to match")
+		})
+	}
+}
@@ -1,26 +1,72 @@
 // Package api provides the HTTP API for working with decks of playing cards.
 // It uses the Gin web framework to handle HTTP requests and the `deck` and `card`
 // packages to create and manage decks of cards. The package exposes endpoints
-// for creating decks, opening decks, and drawing cards from decks.
+// for creating decks, opening decks, drawing cards from decks, and managing the named piles of a deck.
 package api
 
 import (
 	"deck-of-cards/deck"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"time"
 )
 
 type Server struct {
-	store  *deck.Store
+	store  deck.DeckRepository
 	router *gin.Engine
+	// adminToken is the token of the server's administrator, required by the admin endpoints. They are disabled
+	// if it is empty.
+	adminToken string
 }
 
-func NewServer() *Server {
+// Option configures a Server created by NewServer.
+type Option func(*Server)
+
+// WithRepository makes the Server keep its decks in the given repository (e.g. a deck.BoltStore, so decks survive
+// a restart). By default, decks are kept in memory.
+func WithRepository(repository deck.DeckRepository) Option {
+	return func(server *Server) {
+		server.store = repository
+	}
+}
+
+// WithAdminToken enables the admin endpoints (e.g. DELETE /decks), for requests sent with
+// "Authorization: Bearer <token>".
+func WithAdminToken(token string) Option {
+	return func(server *Server) {
+		server.adminToken = token
+	}
+}
+
+func NewServer(opts ...Option) *Server {
 	server := &Server{store: deck.NewStore()}
+	for _, opt := range opts {
+		opt(server)
+	}
 	router := gin.Default()
 
 	router.POST("/deck/new", server.createDeckHandler)
 	router.GET("/deck/:deck_id", server.openDeckHandler)
+	router.DELETE("/deck/:deck_id", server.deleteDeckHandler)
+	router.GET("/decks", server.listDecksHandler)
+	router.DELETE("/decks", server.deleteDecksHandler)
 	router.POST("/deck/:deck_id/draw", server.drawCardHandler)
+	router.GET("/deck/:deck_id/peek", server.peekHandler)
+	router.POST("/deck/:deck_id/deal", server.dealHandler)
+	router.POST("/deck/:deck_id/return", server.returnCardsHandler)
+	router.POST("/deck/:deck_id/insert", server.insertCardsHandler)
+	router.POST("/deck/:deck_id/shuffle", server.shuffleDeckHandler)
+	router.POST("/deck/:deck_id/close", server.closeDeckHandler)
+	router.GET("/deck/:deck_id/proof", server.proofHandler)
+	router.GET("/deck/:deck_id/history", server.historyHandler)
+	router.POST("/deck/:deck_id/undo", server.undoHandler)
+	router.POST("/deck/:deck_id/redo", server.redoHandler)
+	router.GET("/deck/:deck_id/pile/:pile_name", server.listPileHandler)
+	router.POST("/deck/:deck_id/pile/:pile_name/add", server.addToPileHandler)
+	router.POST("/deck/:deck_id/pile/:pile_name/draw", server.drawFromPileHandler)
+	router.POST("/deck/:deck_id/pile/:pile_name/shuffle", server.shufflePileHandler)
+	router.GET("/stats", server.statsHandler)
 
 	server.router = router
 
@@ -30,3 +76,52 @@ func NewServer() *Server {
 func (server *Server) Run(address string) error {
 	return server.router.Run(address)
 }
+
+// getDeck retrieves the deck with the given ID from the store. If it fails, an error response is written, and false
+// is returned.
+func (server *Server) getDeck(c *gin.Context, deckID uuid.UUID) (*deck.Deck, bool) {
+	deckRetrieved, err := server.store.Get(deckID)
+	if err != nil {
+		respondWithError(c, err)
+		return nil, false
+	}
+	return deckRetrieved, true
+}
+
+// errUnchanged is returned by the functions given to updateDeck when they leave the deck unchanged, so the deck is
+// not saved, and its Version is not incremented.
+var errUnchanged = errors.New("deck unchanged")
+
+// updateDeck calls fn with the deck with the given ID, and saves the changes fn made to the deck in the store.
+// It returns the updated deck. If it fails (including when fn fails), an error response is written, and false is
+// returned. If fn returns errUnchanged, the deck is returned as it was, without being saved.
+func (server *Server) updateDeck(c *gin.Context, deckID uuid.UUID, fn func(*deck.Deck) error) (*deck.Deck, bool) {
+	var updatedDeck *deck.Deck
+	var fnErr error
+	err := server.store.Update(deckID, func(d *deck.Deck) error {
+		updatedDeck = d
+		d.SetActor(server.actor(c, d))
+		fnErr = fn(d)
+		return fnErr
+	})
+	if errors.Is(err, errUnchanged) {
+		return updatedDeck, true
+	}
+	if err != nil {
+		if err == fnErr {
+			// Errors from fn are caused by the request (e.g. drawing more cards than remaining).
+			err = rejected(err)
+		}
+		respondWithError(c, err)
+		return nil, false
+	}
+	return updatedDeck, true
+}
+
+// expiresAt returns when the deck expires, or nil if it never expires.
+func expiresAt(d *deck.Deck) *time.Time {
+	if d.ExpiresAt.IsZero() {
+		return nil
+	}
+	return &d.ExpiresAt
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"deck-of-cards/deck"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+)
+
+// maxShuffleTimes is the maximum number of times a deck can be shuffled by one request.
+const maxShuffleTimes = 100
+
+// shuffleDeckHandler is a Gin route handler for shuffling an existing deck.
+// By default, a uniform shuffle first returns every drawn card (including the cards in the deck's piles) to the deck,
+// so the deck can be reused for a new round. With "remaining=true", only the cards left in the deck are shuffled.
+// The other methods are often used in the middle of a game (e.g. a cut), so they only shuffle the cards left in the
+// deck, unless "remaining=false" is passed.
+// The optional "method" query parameter chooses how the deck is shuffled:
+//   - "uniform" (the default) makes every order equally likely.
+//   - "riffle" and "overhand" model shuffling by hand, "times" times (1 by default). See deck.Deck.RiffleShuffle.
+//   - "pile" deals the deck into "piles" piles, and stacks them back.
+//   - "faro" interleaves the two halves of the deck perfectly, with "direction" "out" (the default) or "in".
+//   - "cut" moves the top "position" cards to the bottom.
+//
+// Only the owner of the deck (see ownerToken) can shuffle it: shuffling takes back the hands, and discards the proof of
+// a deck shuffled with a commitment.
+//
+// Example query parameters for shuffling only the remaining cards:
+// /deck/:deck_id/shuffle?remaining=true
+//
+// Example query parameters for seven riffle shuffles:
+// /deck/:deck_id/shuffle?method=riffle&times=7
+func (server *Server) shuffleDeckHandler(c *gin.Context) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	shuffle, err := shuffleFunc(c)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	remaining, err := boolQuery(c, "remaining")
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+	if _, set := c.GetQuery("remaining"); !set {
+		remaining = c.DefaultQuery("method", "uniform") != "uniform"
+	}
+
+	deckRetrieved, updated := server.updateDeck(c, deckID, func(d *deck.Deck) error {
+		if !isOwner(c, d) {
+			return forbidden("only the owner of the deck can shuffle it.")
+		}
+		if !remaining {
+			d.ReturnAll()
+		}
+		return shuffle(d)
+	})
+	if !updated {
+		return
+	}
+
+	jsonResponse := ShuffleDeckResponse{
+		DeckID:    deckRetrieved.ID,
+		Shuffled:  deckRetrieved.Shuffled,
+		Remaining: deckRetrieved.Remaining,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// shuffleFunc returns the function shuffling a deck as requested by the query parameters of shuffleDeckHandler.
+func shuffleFunc(c *gin.Context) (func(d *deck.Deck) error, error) {
+	switch method := c.DefaultQuery("method", "uniform"); method {
+	case "uniform":
+		return func(d *deck.Deck) error {
+			d.Shuffle()
+			return nil
+		}, nil
+	case "riffle", "overhand":
+		times, err := strconv.Atoi(c.DefaultQuery("times", "1"))
+		if err != nil || times <= 0 || times > maxShuffleTimes {
+			return nil, invalidParameter("times", fmt.Sprintf("times parameter must be an integer between 1 and %d", maxShuffleTimes))
+		}
+		shuffleOnce := (*deck.Deck).RiffleShuffle
+		if method == "overhand" {
+			shuffleOnce = (*deck.Deck).OverhandShuffle
+		}
+		return func(d *deck.Deck) error {
+			for i := 0; i < times; i++ {
+				shuffleOnce(d)
+			}
+			return nil
+		}, nil
+	case "pile":
+		piles, err := strconv.Atoi(c.Query("piles"))
+		if err != nil {
+			return nil, invalidParameter("piles", "piles parameter must be an integer")
+		}
+		return func(d *deck.Deck) error {
+			return d.PileShuffle(piles)
+		}, nil
+	case "faro":
+		direction := c.DefaultQuery("direction", "out")
+		if direction != "in" && direction != "out" {
+			return nil, invalidParameter("direction", "direction parameter must be in or out")
+		}
+		return func(d *deck.Deck) error {
+			return d.PerfectFaro(direction == "in")
+		}, nil
+	case "cut":
+		position, err := strconv.Atoi(c.Query("position"))
+		if err != nil {
+			return nil, invalidParameter("position", "position parameter must be an integer")
+		}
+		return func(d *deck.Deck) error {
+			return d.Cut(position)
+		}, nil
+	default:
+		return nil, invalidParameter("method", "method parameter must be one of uniform, riffle, overhand, pile, faro or cut")
+	}
+}
+
+// ShuffleDeckResponse is a struct that represents the JSON response for the shuffleDeckHandler.
+type ShuffleDeckResponse struct {
+	DeckID    uuid.UUID `json:"deck_id"`
+	Shuffled  bool      `json:"shuffled"`
+	Remaining int       `json:"remaining"`
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"deck-of-cards/card"
+	"deck-of-cards/deck"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestShuffleDeck(t *testing.T) {
+	testCases := []struct {
+		name              string
+		params            string
+		expectedRemaining int
+		expectedShuffled  bool
+	}{
+		{
+			name:              "reshuffle the whole deck",
+			params:            "",
+			expectedRemaining: 52,
+			expectedShuffled:  true,
+		},
+		{
+			name:              "shuffle only the remaining cards",
+			params:            "?remaining=true",
+			expectedRemaining: 47,
+			expectedShuffled:  true,
+		},
+		{
+			name:              "cut in the middle of a game",
+			params:            "?method=cut&position=10",
+			expectedRemaining: 47,
+		},
+		{
+			name:              "riffle shuffle in the middle of a game",
+			params:            "?method=riffle",
+			expectedRemaining: 47,
+			expectedShuffled:  true,
+		},
+		{
+			name:              "riffle shuffle of the whole deck",
+			params:            "?method=riffle&remaining=false",
+			expectedRemaining: 52,
+			expectedShuffled:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := setup()
+			deckID, token := createTestDeckWithToken(router, "")
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=5", deckID), nil)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/shuffle%s", deckID, tc.params), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var shuffleResponse ShuffleDeckResponse
+			err := json.NewDecoder(w.Body).Decode(&shuffleResponse)
+			require.NoError(t, err)
+			assert.Equal(t, deckID, shuffleResponse.DeckID)
+			assert.Equal(t, tc.expectedShuffled, shuffleResponse.Shuffled)
+			assert.Equal(t, tc.expectedRemaining, shuffleResponse.Remaining)
+		})
+	}
+}
+
+func TestShuffleDeckNotFound(t *testing.T) {
+	router := setup()
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/shuffle", uuid.NewString()), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestShuffleMethods(t *testing.T) {
+	testCases := []struct {
+		name          string
+		params        string
+		expectedCards string
+	}{
+		{
+			name:          "cut",
+			params:        "?method=cut&position=2",
+			expectedCards: "3S,4S,5S,AS,2S",
+		},
+		{
+			name:          "out faro",
+			params:        "?method=faro",
+			expectedCards: "AS,4S,2S,5S,3S",
+		},
+		{
+			name:          "in faro",
+			params:        "?method=faro&direction=in",
+			expectedCards: "3S,AS,4S,2S,5S",
+		},
+		{
+			name:          "pile",
+			params:        "?method=pile&piles=2",
+			expectedCards: "5S,3S,AS,4S,2S",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := setup()
+			deckID, token := createTestDeckWithToken(router, "?cards=AS,2S,3S,4S,5S")
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/shuffle%s", deckID, tc.params), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			expectedCards, _ := card.FromStrings(strings.Split(tc.expectedCards, ","))
+			assert.Equal(t, expectedCards, openOwnedDeck(t, router, deckID, token).Cards)
+		})
+	}
+
+	// Shuffles by hand keep the same cards.
+	for _, method := range []string{"riffle", "overhand"} {
+		t.Run(method, func(t *testing.T) {
+			router := setup()
+			deckID, token := createTestDeckWithToken(router, "")
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/shuffle?method=%s&times=3", deckID, method), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var shuffleResponse ShuffleDeckResponse
+			err := json.NewDecoder(w.Body).Decode(&shuffleResponse)
+			require.NoError(t, err)
+			assert.True(t, shuffleResponse.Shuffled)
+			assert.Equal(t, 52, shuffleResponse.Remaining)
+			assert.ElementsMatch(t, deck.NewStandardDeck().Cards, openOwnedDeck(t, router, deckID, token).Cards)
+		})
+	}
+}
+
+func TestShuffleMethodsInvalidRequests(t *testing.T) {
+	router := setup()
+	deckID, deckToken := createTestDeckWithToken(router, "?cards=AS,2S,3S,4S,5S")
+
+	testCases := []struct {
+		name   string
+		params string
+		// token is sent instead of the owner token of the deck, if it is not empty.
+		token        string
+		expectedCode int
+	}{
+		{
+			name:         "unknown method",
+			params:       "?method=spin",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "too many riffles",
+			params:       "?method=riffle&times=1000",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "pile without piles",
+			params:       "?method=pile",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "a single pile",
+			params:       "?method=pile&piles=1",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown faro direction",
+			params:       "?method=faro&direction=sideways",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "remaining is not a boolean",
+			params:       "?remaining=yes",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "cut outside of the deck",
+			params:       "?method=cut&position=5",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "not the owner",
+			token:        "not-the-owner-token",
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/shuffle%s", deckID, tc.params), nil)
+			token := deckToken
+			if tc.token != "" {
+				token = tc.token
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code, "Expected status code to match")
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"deck-of-cards/deck"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// statsProvider is implemented by the repositories which keep usage counters, such as deck.Store.
+type statsProvider interface {
+	Stats() deck.StoreStats
+}
+
+// statsHandler is a Gin route handler for retrieving the usage counters of the deck store: how many decks and cards
+// it holds, and how many decks were evicted, expired or rejected. It returns 404 if the store does not keep counters.
+func (server *Server) statsHandler(c *gin.Context) {
+	provider, ok := server.store.(statsProvider)
+	if !ok {
+		respondWithError(c, &apiError{status: http.StatusNotFound, code: "stats_unavailable", message: "stats are not available for this store."})
+		return
+	}
+
+	stats := provider.Stats()
+	jsonResponse := StatsResponse{
+		Decks:    stats.Decks,
+		Cards:    stats.Cards,
+		Evicted:  stats.Evicted,
+		Expired:  stats.Expired,
+		Rejected: stats.Rejected,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// StatsResponse is a struct that represents the JSON response for the statsHandler.
+type StatsResponse struct {
+	Decks    int    `json:"decks"`
+	Cards    int    `json:"cards"`
+	Evicted  uint64 `json:"evicted"`
+	Expired  uint64 `json:"expired"`
+	Rejected uint64 `json:"rejected"`
+}
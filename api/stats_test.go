@@ -0,0 +1,71 @@
+package api
+
+import (
+	"deck-of-cards/deck"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateDeckWhenStoreIsFull(t *testing.T) {
+	testCases := []struct {
+		name          string
+		whenFull      deck.FullPolicy
+		expectedCode  int
+		expectedStats StatsResponse
+	}{
+		{
+			name:          "reject new decks",
+			whenFull:      deck.RejectWhenFull,
+			expectedCode:  http.StatusInsufficientStorage,
+			expectedStats: StatsResponse{Decks: 2, Cards: 104, Rejected: 1},
+		},
+		{
+			name:          "evict least recently used decks",
+			whenFull:      deck.EvictLeastRecentlyUsed,
+			expectedCode:  http.StatusOK,
+			expectedStats: StatsResponse{Decks: 2, Cards: 104, Evicted: 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := deck.NewStoreWithConfig(deck.StoreConfig{MaxDecks: 2, WhenFull: tc.whenFull})
+			defer store.Close()
+			router := setup(WithRepository(store))
+
+			createTestDeck(router, "")
+			createTestDeck(router, "")
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/deck/new", nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tc.expectedCode, w.Code)
+
+			w = httptest.NewRecorder()
+			req = httptest.NewRequest(http.MethodGet, "/stats", nil)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var statsResponse StatsResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&statsResponse))
+			assert.Equal(t, tc.expectedStats, statsResponse)
+		})
+	}
+}
+
+func TestStatsNotAvailable(t *testing.T) {
+	store, err := deck.NewBoltStore(filepath.Join(t.TempDir(), "decks.db"))
+	require.NoError(t, err)
+	defer store.Close()
+	router := setup(WithRepository(store))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
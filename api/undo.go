@@ -0,0 +1,81 @@
+package api
+
+import (
+	"deck-of-cards/deck"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+)
+
+// undoHandler is a Gin route handler for reverting the last operations on an existing deck (e.g. a misclicked draw),
+// restoring its cards exactly as they were before (see deck.Deck.Undo). Each request which changed the deck is one
+// operation. The optional "count" query parameter is the number of operations to undo (1 by default).
+// Only the owner of the deck (see ownerToken) can undo operations, so players can not undo the dealer.
+// Closing the deck, or exhausting it once shuffled with a commitment, can not be undone: its order may have been
+// revealed (see proofHandler).
+//
+// Example query parameters for undoing the last two operations:
+// /deck/:deck_id/undo?count=2
+func (server *Server) undoHandler(c *gin.Context) {
+	server.rewindHandler(c, (*deck.Deck).Undo, "undo")
+}
+
+// redoHandler is a Gin route handler for replaying the last operations undone with undoHandler, as long as the deck
+// did not change since (see deck.Deck.Redo). It accepts the same query parameters as undoHandler, and is only
+// available to the owner of the deck too.
+func (server *Server) redoHandler(c *gin.Context) {
+	server.rewindHandler(c, (*deck.Deck).Redo, "redo")
+}
+
+// rewindHandler undoes (or redoes) operations on a deck with rewind, for undoHandler and redoHandler.
+func (server *Server) rewindHandler(c *gin.Context, rewind func(d *deck.Deck, n int) ([]uint64, error), action string) {
+	deckID, err := uuid.Parse(c.Param("deck_id"))
+	if err != nil {
+		respondWithError(c, errInvalidDeckID)
+		return
+	}
+
+	count, err := strconv.Atoi(c.DefaultQuery("count", "1"))
+	if err != nil || count <= 0 {
+		respondWithError(c, invalidParameter("count", "count parameter must be a positive integer"))
+		return
+	}
+
+	var versions []uint64
+	updatedDeck, updated := server.updateDeck(c, deckID, func(d *deck.Deck) error {
+		if !isOwner(c, d) {
+			return forbidden("only the owner of the deck can " + action + " its operations.")
+		}
+
+		var err error
+		versions, err = rewind(d, count)
+		return err
+	})
+	if !updated {
+		return
+	}
+
+	jsonResponse := UndoResponse{
+		DeckID:    updatedDeck.ID,
+		Versions:  versions,
+		Remaining: updatedDeck.Remaining,
+		Shuffled:  updatedDeck.Shuffled,
+		Version:   updatedDeck.Version,
+	}
+	c.JSON(http.StatusOK, jsonResponse)
+}
+
+// UndoResponse is a struct that represents the JSON response for the undoHandler and the redoHandler. It holds the
+// state of the deck once the operations are undone (or redone).
+type UndoResponse struct {
+	DeckID uuid.UUID `json:"deck_id"`
+	// Versions holds the versions of the deck made by the undone (or redone) operations, from the first one undone
+	// (or redone). They are listed by historyHandler.
+	Versions  []uint64 `json:"versions"`
+	Remaining int      `json:"remaining"`
+	Shuffled  bool     `json:"shuffled"`
+	// Version is incremented each time the deck changes, including when operations are undone (see
+	// DrawCardsResponse).
+	Version uint64 `json:"version"`
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUndo(t *testing.T) {
+	router := setup()
+	deckID, token := createTestDeckWithToken(router, "?shuffled=true")
+	created := openOwnedDeck(t, router, deckID, token)
+
+	post := func(target string, token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// A misclicked draw is undone, and the same cards are drawn again.
+	w := post(fmt.Sprintf("/deck/%s/draw?count=5", deckID), token)
+	require.Equal(t, http.StatusOK, w.Code)
+	var firstDraw DrawCardsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&firstDraw))
+
+	w = post(fmt.Sprintf("/deck/%s/undo", deckID), token)
+	require.Equal(t, http.StatusOK, w.Code)
+	var undoResponse UndoResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&undoResponse))
+	assert.Equal(t, deckID, undoResponse.DeckID)
+	assert.Equal(t, []uint64{1}, undoResponse.Versions)
+	assert.Equal(t, 52, undoResponse.Remaining)
+	assert.True(t, undoResponse.Shuffled)
+	assert.Equal(t, uint64(2), undoResponse.Version)
+	assert.Equal(t, created.Cards, openOwnedDeck(t, router, deckID, token).Cards)
+
+	w = post(fmt.Sprintf("/deck/%s/draw?count=5", deckID), token)
+	require.Equal(t, http.StatusOK, w.Code)
+	var secondDraw DrawCardsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&secondDraw))
+	assert.Equal(t, firstDraw.Cards, secondDraw.Cards)
+
+	// Several operations (two shuffles) are undone at once.
+	require.Equal(t, http.StatusOK, post(fmt.Sprintf("/deck/%s/shuffle", deckID), token).Code)
+	require.Equal(t, http.StatusOK, post(fmt.Sprintf("/deck/%s/shuffle?method=riffle", deckID), token).Code)
+	w = post(fmt.Sprintf("/deck/%s/undo?count=2", deckID), token)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&undoResponse))
+	assert.Equal(t, 47, undoResponse.Remaining)
+
+	// And redone.
+	w = post(fmt.Sprintf("/deck/%s/redo?count=2", deckID), token)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&undoResponse))
+	assert.Equal(t, 52, undoResponse.Remaining)
+
+	// The history lists the undone and redone operations, and still leads to the deck.
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/deck/%s/history", deckID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var historyResponse HistoryResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&historyResponse))
+	assert.True(t, historyResponse.Verified)
+	lastEvent := historyResponse.Events[len(historyResponse.Events)-1]
+	assert.Equal(t, "redone", lastEvent.Type)
+	assert.Equal(t, "owner", lastEvent.Actor)
+	assert.Equal(t, []uint64{4, 5}, lastEvent.Versions, "The most recently undone operation is redone first")
+}
+
+func TestUndoInvalidRequests(t *testing.T) {
+	router := setup()
+	deckID, token := createTestDeckWithToken(router, "")
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=1", deckID), nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	testCases := []struct {
+		name         string
+		target       string
+		token        string
+		expectedCode int
+	}{
+		{
+			name:         "without owner token",
+			target:       fmt.Sprintf("/deck/%s/undo", deckID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "redo without owner token",
+			target:       fmt.Sprintf("/deck/%s/redo", deckID),
+			token:        "not-the-owner-token",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "more operations than the deck went through",
+			target:       fmt.Sprintf("/deck/%s/undo?count=2", deckID),
+			token:        token,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "nothing to redo",
+			target:       fmt.Sprintf("/deck/%s/redo", deckID),
+			token:        token,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "non-positive count",
+			target:       fmt.Sprintf("/deck/%s/undo?count=0", deckID),
+			token:        token,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "deck not found",
+			target:       fmt.Sprintf("/deck/%s/undo", uuid.NewString()),
+			token:        token,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.target, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedCode, w.Code, "Expected status code to match")
+		})
+	}
+}
+
+func TestUndoRevealedOrder(t *testing.T) {
+	router := setup()
+	deckID, token := createTestDeckWithToken(router, "?shuffled=true")
+
+	send := func(method string, target string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusOK, send(http.MethodPost, fmt.Sprintf("/deck/%s/close", deckID)))
+	require.Equal(t, http.StatusOK, send(http.MethodGet, fmt.Sprintf("/deck/%s/proof", deckID)))
+
+	// Everyone who got the proof knows the order of the deck, so it can not be reopened.
+	assert.Equal(t, http.StatusConflict, send(http.MethodPost, fmt.Sprintf("/deck/%s/undo", deckID)))
+	assert.Equal(t, http.StatusConflict, send(http.MethodPost, fmt.Sprintf("/deck/%s/draw?count=1", deckID)))
+}
@@ -1,5 +1,5 @@
 // Package card provides types and functions for working with standard playing cards.
-// It defines the Card, Rank, and Suit types, along with various utility functions
+// It defines the Card, Rank, and Suit types (including Jokers), along with various utility functions
 // for creating and validating cards, as well as converting between short and long
 // string representations of ranks and suits.
 //
@@ -15,15 +15,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 )
 
+// ErrInvalidCode is returned by FromStrings when a code is not a valid card code.
+var ErrInvalidCode = errors.New("invalid card code")
+
 // Card represents a single playing card with a rank and suit.
 type Card struct {
 	Rank Rank
 	Suit Suit
 }
 
-// FromString creates a Card instance from a string input (e.g., "4H" for the Four of Hearts).
+// FromString creates a Card instance from a string input (e.g., "4H" for the Four of Hearts, "X2" for the red Joker).
 // It returns an error if the input string is invalid.
 func FromString(s string) (Card, error) {
 	// All codes have at least two characters (one for Rank, one for Suit).
@@ -48,7 +52,50 @@ func FromString(s string) (Card, error) {
 		return Card{}, fmt.Errorf("invalid suit string: %c", suitStr)
 	}
 
-	return Card{Rank: rank, Suit: suit}, nil
+	c := Card{Rank: rank, Suit: suit}
+	if !c.IsValid() {
+		return Card{}, fmt.Errorf("invalid card string: %s", s)
+	}
+
+	return c, nil
+}
+
+// Jokers returns the two Jokers of a deck, in order (X1 is the black Joker, X2 the red one).
+func Jokers() []Card {
+	return []Card{{Rank: Joker, Suit: Black}, {Rank: Joker, Suit: Red}}
+}
+
+// IsJoker checks whether the Card is a Joker.
+func (c Card) IsJoker() bool {
+	return c.Rank == Joker
+}
+
+// IsValid checks whether the Card is a valid combination of Rank and Suit.
+// Jokers only go with the Joker suits, and every other Rank only goes with the standard suits.
+func (c Card) IsValid() bool {
+	if !c.Rank.IsValid() || !c.Suit.IsValid() {
+		return false
+	}
+	if c.IsJoker() {
+		return c.Suit.isJoker()
+	}
+	return c.Suit.isStandard()
+}
+
+// FromStrings creates a Card instance for each of the given codes, keeping their order.
+// It returns an error naming the first invalid code, which wraps ErrInvalidCode.
+func FromStrings(codes []string) ([]Card, error) {
+	cards := make([]Card, 0, len(codes))
+
+	for _, code := range codes {
+		c, err := FromString(code)
+		if err != nil {
+			return nil, fmt.Errorf("%w '%s': %v", ErrInvalidCode, code, err)
+		}
+		cards = append(cards, c)
+	}
+
+	return cards, nil
 }
 
 // MarshalJSON customizes the JSON marshaling of the Card struct. It returns a JSON object
@@ -90,14 +137,44 @@ func (c *Card) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	if !(Card{Rank: rank, Suit: suit}).IsValid() {
+		return fmt.Errorf("invalid card: %s of %s", cardJSON.Value, cardJSON.Suit)
+	}
+
 	c.Rank = rank
 	c.Suit = suit
 
 	return nil
 }
 
-// String returns a string representation of the Card (e.g., "4H" for the Four of Hearts).
+// String returns a string representation of the Card (e.g., "4H" for the Four of Hearts, "X1" for the black Joker).
 // This is also referred as the `Code` of the Card.
 func (c Card) String() string {
 	return fmt.Sprintf("%s%s", c.Rank, c.Suit)
 }
+
+// Sort sorts the cards in the order of a new deck: by Suit (Spades, Diamonds, Clubs, Hearts), then by Rank (Ace
+// first). Jokers come last, the black one first.
+func Sort(cards []Card) {
+	sort.SliceStable(cards, func(i, j int) bool {
+		return cards[i].order() < cards[j].order()
+	})
+}
+
+// order returns the position of the Card in a new deck (see Sort).
+func (c Card) order() int {
+	if c.IsJoker() {
+		return len(Suits())*len(Ranks()) + indexOf(JokerSuits(), c.Suit)
+	}
+	return indexOf(Suits(), c.Suit)*len(Ranks()) + indexOf(Ranks(), c.Rank)
+}
+
+// indexOf returns the index of value in values, or -1 if it is not there.
+func indexOf[T comparable](values []T, value T) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -17,6 +17,7 @@ func TestCardString(t *testing.T) {
 		{"Ten of Hearts -> TH", Card{Suit: Hearts, Rank: Ten}, "TH"},
 		{"Queen of Diamonds -> QD", Card{Suit: Diamonds, Rank: Queen}, "QD"},
 		{"Jack of Clubs -> JC", Card{Suit: Clubs, Rank: Jack}, "JC"},
+		{"Black Joker -> X1", Card{Suit: Black, Rank: Joker}, "X1"},
 	}
 
 	for _, tc := range testCases {
@@ -39,6 +40,8 @@ func TestCardFromStringValid(t *testing.T) {
 		{"9C -> Nine of Clubs", "9C", Card{Rank: Nine, Suit: Clubs}},
 		{"2S -> Two of Spades", "2S", Card{Rank: Two, Suit: Spades}},
 		{"TS -> Ten of Spades", "TS", Card{Rank: Ten, Suit: Spades}},
+		{"X1 -> Black Joker", "X1", Card{Rank: Joker, Suit: Black}},
+		{"X2 -> Red Joker", "X2", Card{Rank: Joker, Suit: Red}},
 	}
 
 	for _, tc := range testCases {
@@ -61,10 +64,13 @@ func TestCardFromStringInvalid(t *testing.T) {
 		name  string
 		input string
 	}{
-		{"invalid rank string X", "XH"},
+		{"invalid rank string Z", "ZH"},
+		{"Joker with a standard suit", "XH"},
+		{"standard rank with a Joker suit", "A1"},
+		{"Joker with an unknown color", "X3"},
 		{"invalid suit string $", "A$"},
 		{"empty card string", ""},
-		{"both rank and suit invalid", "X$"},
+		{"both rank and suit invalid", "Z$"},
 		// TODO: UTF-8 handling may require some more investigation. I am not sure how string slices of UTF-8 work in Go.
 		{"UTF-8 invalid rank string", "ǶH"},
 		{"UTF-8 invalid suit string", "A♡"},
@@ -78,6 +84,15 @@ func TestCardFromStringInvalid(t *testing.T) {
 	}
 }
 
+func TestCardFromStrings(t *testing.T) {
+	cards, err := FromStrings([]string{"AS", "TH", "2C"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Card{{Rank: Ace, Suit: Spades}, {Rank: Ten, Suit: Hearts}, {Rank: Two, Suit: Clubs}}, cards)
+
+	_, err = FromStrings([]string{"AS", "XH"})
+	assert.Error(t, err, "Any invalid code should return an error")
+}
+
 func TestCardMarshalJSON(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -87,6 +102,7 @@ func TestCardMarshalJSON(t *testing.T) {
 		{"Queen of Hearts", Card{Rank: Queen, Suit: Hearts}, `{"value":"QUEEN","suit":"HEARTS","code":"QH"}`},
 		{"Ace of Spades", Card{Rank: Ace, Suit: Spades}, `{"value":"ACE","suit":"SPADES","code":"AS"}`},
 		{"Ten of Spades", Card{Rank: Ten, Suit: Spades}, `{"value":"TEN","suit":"SPADES","code":"TS"}`},
+		{"Red Joker", Card{Rank: Joker, Suit: Red}, `{"value":"JOKER","suit":"RED","code":"X2"}`},
 	}
 
 	for _, tc := range testCases {
@@ -107,6 +123,7 @@ func TestCardUnmarshalJSON(t *testing.T) {
 		{"JSON -> Queen of Hearts", `{"value":"QUEEN","suit":"HEARTS","code":"QH"}`, Card{Rank: Queen, Suit: Hearts}},
 		{"JSON -> Ace of Spades", `{"value":"ACE","suit":"SPADES","code":"AS"}`, Card{Rank: Ace, Suit: Spades}},
 		{"JSON -> Ten of Clubs", `{"value":"TEN","suit":"CLUBS","code":"10"}`, Card{Rank: Ten, Suit: Clubs}},
+		{"JSON -> Black Joker", `{"value":"JOKER","suit":"BLACK","code":"X1"}`, Card{Rank: Joker, Suit: Black}},
 	}
 
 	for _, tc := range testCases {
@@ -119,3 +136,21 @@ func TestCardUnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestCardUnmarshalJSONInvalidJoker(t *testing.T) {
+	var c Card
+	err := json.Unmarshal([]byte(`{"value":"JOKER","suit":"HEARTS"}`), &c)
+	assert.Error(t, err, "A Joker can not have a standard suit")
+
+	err = json.Unmarshal([]byte(`{"value":"ACE","suit":"RED"}`), &c)
+	assert.Error(t, err, "Only Jokers have a Joker suit")
+}
+
+func TestSort(t *testing.T) {
+	cards, _ := FromStrings([]string{"X2", "KH", "AS", "X1", "2C", "TD", "AS"})
+
+	Sort(cards)
+
+	expected, _ := FromStrings([]string{"AS", "AS", "TD", "2C", "KH", "X1", "X2"})
+	assert.Equal(t, expected, cards, "Cards are sorted by suit, then by rank, with Jokers last")
+}
@@ -22,15 +22,20 @@ const (
 	Jack  Rank = "J"
 	Queen Rank = "Q"
 	King  Rank = "K"
+	Joker Rank = "X" // Jokers do not have a regular Suit, see JokerSuits.
 )
 
-// Ranks returns a slice of all valid Rank values, in order (Ace first).
+// Ranks returns a slice of all valid Rank values of a standard deck, in order (Ace first).
+// Joker is not part of a standard deck, and is not included.
 func Ranks() []Rank {
 	return []Rank{Ace, Two, Three, Four, Five, Six, Seven, Eight, Nine, Ten, Jack, Queen, King}
 }
 
-// IsValid checks whether the Rank is a valid value.
+// IsValid checks whether the Rank is a valid value (including Joker).
 func (r Rank) IsValid() bool {
+	if r == Joker {
+		return true
+	}
 	for _, validRank := range Ranks() {
 		if r == validRank {
 			return true
@@ -68,6 +73,8 @@ func (r Rank) LongString() string {
 		return "QUEEN"
 	case King:
 		return "KING"
+	case Joker:
+		return "JOKER"
 	default:
 		return ""
 	}
@@ -103,6 +110,8 @@ func ParseLongRank(r string) (Rank, error) {
 		return Queen, nil
 	case "KING":
 		return King, nil
+	case "JOKER":
+		return Joker, nil
 	default:
 		return "", fmt.Errorf("could not parse Rank from string: %s", r)
 	}
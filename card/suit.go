@@ -13,15 +13,30 @@ const (
 	Diamonds Suit = "D"
 	Clubs    Suit = "C"
 	Hearts   Suit = "H"
+
+	// Jokers are told apart by their color instead of a regular suit (X1 is the black Joker, X2 the red one).
+	Black Suit = "1"
+	Red   Suit = "2"
 )
 
-// Suits returns a slice of all valid Suit values, in order (Spades, Diamonds, Clubs, Hearts).
+// Suits returns a slice of all valid Suit values of a standard deck, in order (Spades, Diamonds, Clubs, Hearts).
+// The Joker suits are not included, see JokerSuits.
 func Suits() []Suit {
 	return []Suit{Spades, Diamonds, Clubs, Hearts}
 }
 
-// IsValid checks whether the Suit is a valid value.
+// JokerSuits returns a slice of the Suit values used by Jokers, in order (Black, Red).
+func JokerSuits() []Suit {
+	return []Suit{Black, Red}
+}
+
+// IsValid checks whether the Suit is a valid value (including the Joker suits).
 func (s Suit) IsValid() bool {
+	return s.isStandard() || s.isJoker()
+}
+
+// isStandard checks whether the Suit is one of the suits of a standard deck.
+func (s Suit) isStandard() bool {
 	for _, validSuit := range Suits() {
 		if s == validSuit {
 			return true
@@ -30,7 +45,18 @@ func (s Suit) IsValid() bool {
 	return false
 }
 
-// LongString returns the long form string representation of the Suit (e.g., "SPADES", "DIAMONDS", "CLUBS", "HEARTS").
+// isJoker checks whether the Suit is one of the suits used by Jokers.
+func (s Suit) isJoker() bool {
+	for _, validSuit := range JokerSuits() {
+		if s == validSuit {
+			return true
+		}
+	}
+	return false
+}
+
+// LongString returns the long form string representation of the Suit (e.g., "SPADES", "DIAMONDS", "CLUBS", "HEARTS",
+// and "BLACK" or "RED" for Jokers).
 func (s Suit) LongString() string {
 	switch s {
 	case Spades:
@@ -41,6 +67,10 @@ func (s Suit) LongString() string {
 		return "CLUBS"
 	case Hearts:
 		return "HEARTS"
+	case Black:
+		return "BLACK"
+	case Red:
+		return "RED"
 	default:
 		return ""
 	}
@@ -58,6 +88,10 @@ func ParseLongSuit(s string) (Suit, error) {
 		return Clubs, nil
 	case "HEARTS":
 		return Hearts, nil
+	case "BLACK":
+		return Black, nil
+	case "RED":
+		return Red, nil
 	default:
 		return "", fmt.Errorf("could not parse Suit from string: %s", s)
 	}
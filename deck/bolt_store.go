@@ -0,0 +1,310 @@
+package deck
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+	"time"
+)
+
+// decksBucket is the BoltDB bucket holding the decks, keyed by ID.
+var decksBucket = []byte("decks")
+
+// archiveBucket is the BoltDB bucket holding the events moved out of the History of the decks (see EventCompacted).
+// It holds a bucket for each deck, keyed by ID, whose events are keyed by Sequence.
+var archiveBucket = []byte("archive")
+
+// BoltStore manages a collection of decks in a BoltDB file on disk, so decks survive a restart of the server.
+// It implements DeckRepository. BoltDB transactions are serializable, so BoltStore is safe for concurrent use.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens (or creates) the BoltDB file at path, and returns a BoltStore backed by it.
+// Only one process can open the file at a time. The BoltStore must be closed with Close.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("could not open deck store at %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(decksBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(archiveBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("could not initialize deck store at %s: %w", path, err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// Close closes the BoltDB file.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// Add adds a new deck to the store. It returns an error if a deck with the same ID already exists in the store.
+func (s *BoltStore) Add(deck *Deck) error {
+	if deck == nil {
+		return errors.New("deck pointer can not be nil")
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(decksBucket)
+		if bucket.Get(deck.ID[:]) != nil {
+			return errors.New("deck ID already exists in the store")
+		}
+		return putDeck(bucket, deck)
+	})
+}
+
+// Get retrieves a deck from the store by its ID. It returns ErrDeckNotFound if the deck is not found.
+func (s *BoltStore) Get(id uuid.UUID) (*Deck, error) {
+	var deck *Deck
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		deck, err = getDeck(tx.Bucket(decksBucket), id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deck, nil
+}
+
+// Remove removes a deck from the store by its ID. It returns ErrDeckNotFound if the deck is not found.
+func (s *BoltStore) Remove(id uuid.UUID) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(decksBucket)
+		if bucket.Get(id[:]) == nil {
+			return ErrDeckNotFound
+		}
+		if err := bucket.Delete(id[:]); err != nil {
+			return err
+		}
+		return deleteArchive(tx, id[:])
+	})
+}
+
+// RemoveMatching removes every deck matching filter from the file, and returns how many decks were removed.
+func (s *BoltStore) RemoveMatching(filter DeckFilter) (int, error) {
+	removed := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(decksBucket)
+		// Keys can not be deleted while iterating over the bucket, so they are collected first.
+		var ids [][]byte
+		err := bucket.ForEach(func(k, data []byte) error {
+			deck, err := decodeDeck(data)
+			if err != nil {
+				return err
+			}
+			if filter.matches(deck) {
+				ids = append(ids, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if err := bucket.Delete(id); err != nil {
+				return err
+			}
+			if err := deleteArchive(tx, id); err != nil {
+				return err
+			}
+		}
+		removed = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// Update calls fn with the deck with the given ID, and saves the deck if fn succeeds. Both happen in the same
+// transaction, so concurrent updates of a deck do not overwrite each other.
+// It returns ErrDeckNotFound if the deck is not found, or the error returned by fn.
+func (s *BoltStore) Update(id uuid.UUID, fn func(*Deck) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(decksBucket)
+		deck, err := getDeck(bucket, id)
+		if err != nil {
+			return err
+		}
+		version := deck.Version
+		recorded := len(deck.History)
+
+		if err := fn(deck); err != nil {
+			return err
+		}
+		if deck.ID != id {
+			return errors.New("the ID of a deck can not be updated")
+		}
+		deck.bumpVersion(version, recorded)
+		if err := archiveEvents(tx, id, deck.compactHistory()); err != nil {
+			return err
+		}
+
+		return putDeck(bucket, deck)
+	})
+}
+
+// ArchivedHistory returns the events moved out of the History of the deck with the given ID whose Sequence is greater
+// than after, at most limit of them unless limit is zero. It returns ErrDeckNotFound if the deck is not found.
+func (s *BoltStore) ArchivedHistory(id uuid.UUID, after int, limit int) ([]Event, error) {
+	var events []Event
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(decksBucket).Get(id[:]) == nil {
+			return ErrDeckNotFound
+		}
+		archive := tx.Bucket(archiveBucket).Bucket(id[:])
+		if archive == nil {
+			return nil
+		}
+
+		cursor := archive.Cursor()
+		for k, data := cursor.Seek(sequenceKey(after + 1)); k != nil; k, data = cursor.Next() {
+			if limit > 0 && len(events) == limit {
+				break
+			}
+			var event Event
+			if err := json.Unmarshal(data, &event); err != nil {
+				return fmt.Errorf("could not decode event: %w", err)
+			}
+			events = append(events, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// List returns a page of the decks matching filter, from the oldest to the newest, and the cursor of the next page
+// (see DeckRepository).
+func (s *BoltStore) List(filter DeckFilter, page Page) ([]*Deck, string, error) {
+	var decks []*Deck
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(decksBucket).ForEach(func(k, data []byte) error {
+			deck, err := decodeDeck(data)
+			if err != nil {
+				return err
+			}
+			if filter.matches(deck) {
+				decks = append(decks, deck)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	sortDecks(decks)
+	return paginate(decks, page)
+}
+
+// storedDeck is the representation of a Deck in a BoltStore.
+// On top of the Deck's exported fields, it keeps the state of a SeededShuffler, so a seeded Deck keeps shuffling
+// exactly as it would have in memory.
+type storedDeck struct {
+	Deck
+	SeededShuffler *storedShuffler `json:",omitempty"`
+}
+
+// storedShuffler is the state of a SeededShuffler: its seed, and the state of its source.
+type storedShuffler struct {
+	Seed  int64
+	State uint64
+}
+
+// putDeck saves the deck in the bucket, under its ID.
+func putDeck(bucket *bolt.Bucket, deck *Deck) error {
+	stored := storedDeck{Deck: *deck}
+	switch shuffler := deck.shuffler.(type) {
+	case nil, SecureShuffler:
+	case *SeededShuffler:
+		stored.SeededShuffler = &storedShuffler{Seed: shuffler.source.seed, State: shuffler.source.state}
+	default:
+		return fmt.Errorf("shuffler %T can not be stored", shuffler)
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("could not encode deck: %w", err)
+	}
+	return bucket.Put(deck.ID[:], data)
+}
+
+// getDeck reads the deck with the given ID from the bucket. It returns ErrDeckNotFound if there is no such deck.
+func getDeck(bucket *bolt.Bucket, id uuid.UUID) (*Deck, error) {
+	data := bucket.Get(id[:])
+	if data == nil {
+		return nil, ErrDeckNotFound
+	}
+	return decodeDeck(data)
+}
+
+// decodeDeck decodes a deck saved by putDeck.
+func decodeDeck(data []byte) (*Deck, error) {
+	var stored storedDeck
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("could not decode deck: %w", err)
+	}
+
+	deck := stored.Deck
+	if stored.SeededShuffler != nil {
+		deck.shuffler = restoreSeededShuffler(stored.SeededShuffler.Seed, stored.SeededShuffler.State)
+	}
+	return &deck, nil
+}
+
+// archiveEvents saves the events moved out of the History of the deck with the given ID (see compactHistory) in the
+// archive bucket.
+func archiveEvents(tx *bolt.Tx, id uuid.UUID, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	archive, err := tx.Bucket(archiveBucket).CreateBucketIfNotExists(id[:])
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("could not encode event: %w", err)
+		}
+		if err := archive.Put(sequenceKey(event.Sequence), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteArchive deletes the archived events of the deck with the given ID, if any.
+func deleteArchive(tx *bolt.Tx, id []byte) error {
+	err := tx.Bucket(archiveBucket).DeleteBucket(id)
+	if errors.Is(err, bolt.ErrBucketNotFound) {
+		return nil
+	}
+	return err
+}
+
+// sequenceKey returns the key of the archived event with the given Sequence. Keys are big-endian, so the events are
+// sorted by Sequence.
+func sequenceKey(sequence int) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(sequence))
+}
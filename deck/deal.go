@@ -0,0 +1,65 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"errors"
+	"fmt"
+)
+
+// Deal draws cardsEach cards for each of the players from the top of the Deck, and places them on the players' piles
+// (their hands), which are created if they do not exist yet. With roundRobin, cards are dealt one at a time to each
+// player in turn, as at a card table. Otherwise, each player gets the next cardsEach cards in one go.
+// It returns the cards dealt to each player, in the order they were dealt, keyed by player.
+// It returns the same errors as Draw, for the cards of every player. In that case, nothing is dealt.
+func (d *Deck) Deal(players []string, cardsEach int, roundRobin bool) (map[string][]card.Card, error) {
+	if len(players) == 0 {
+		return nil, errors.New("at least one player must be dealt cards")
+	}
+
+	if cardsEach <= 0 {
+		return nil, fmt.Errorf("number of cards for each player should be positive")
+	}
+
+	seen := make(map[string]bool, len(players))
+	for _, player := range players {
+		if player == "" {
+			return nil, errors.New("player name can not be empty")
+		}
+		if seen[player] {
+			return nil, fmt.Errorf("player '%s' can not be dealt twice", player)
+		}
+		seen[player] = true
+	}
+
+	if d.Closed {
+		return nil, ErrDeckClosed
+	}
+	// The number of cards to deal is only computed once it is known to fit in the Deck, as it could overflow.
+	if cardsEach > d.Drawable()/len(players) {
+		return nil, fmt.Errorf("%w to deal %d cards to each of the %d players", ErrNotEnoughCards, cardsEach, len(players))
+	}
+
+	drawnCards, err := d.draw(len(players) * cardsEach)
+	if err != nil {
+		return nil, err
+	}
+	// draw keeps track of the drawn cards, but these are now in the players' piles.
+	d.Drawn = d.Drawn[:len(d.Drawn)-len(drawnCards)]
+
+	hands := make(map[string][]card.Card, len(players))
+	for i, c := range drawnCards {
+		player := players[i/cardsEach]
+		if roundRobin {
+			player = players[i%len(players)]
+		}
+		hands[player] = append(hands[player], c)
+	}
+
+	for _, player := range players {
+		pile, _ := d.pileOrCreate(player)
+		pile.add(hands[player])
+		d.record(Event{Type: EventDealt, Cards: hands[player], Pile: player})
+	}
+
+	return hands, nil
+}
@@ -0,0 +1,136 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestDeal(t *testing.T) {
+	testCases := []struct {
+		name          string
+		roundRobin    bool
+		expectedHands map[string][]string
+	}{
+		{
+			name:       "round robin",
+			roundRobin: true,
+			expectedHands: map[string][]string{
+				"alice": {"AS", "3S", "5S"},
+				"bob":   {"2S", "4S", "6S"},
+			},
+		},
+		{
+			name:       "in one go",
+			roundRobin: false,
+			expectedHands: map[string][]string{
+				"alice": {"AS", "2S", "3S"},
+				"bob":   {"4S", "5S", "6S"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deck, _ := NewPartialDeck([]string{"AS", "2S", "3S", "4S", "5S", "6S", "7S"})
+
+			hands, err := deck.Deal([]string{"alice", "bob"}, 3, tc.roundRobin)
+			require.NoError(t, err)
+
+			for player, codes := range tc.expectedHands {
+				expectedCards, _ := card.FromStrings(codes)
+				assert.Equal(t, expectedCards, hands[player], "Cards are returned in the order they were dealt")
+
+				pile, err := deck.Pile(player)
+				require.NoError(t, err)
+				assert.ElementsMatch(t, expectedCards, pile.Cards, "Dealt cards are in the player's pile")
+				assert.Equal(t, expectedCards[len(expectedCards)-1], pile.Cards[0], "The last dealt card is on top")
+			}
+
+			assert.Equal(t, 1, deck.Remaining)
+			assert.Empty(t, deck.Drawn, "Dealt cards are in the players' piles, not drawn")
+			assert.Equal(t, 7, deck.Size())
+		})
+	}
+}
+
+func TestDealAddsToExistingHands(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "2S", "3S", "4S"})
+
+	_, err := deck.Deal([]string{"alice", "bob"}, 1, true)
+	require.NoError(t, err)
+	_, err = deck.Deal([]string{"bob", "alice"}, 1, true)
+	require.NoError(t, err)
+
+	alice, _ := deck.Pile("alice")
+	bob, _ := deck.Pile("bob")
+	assert.Equal(t, []card.Card{{Rank: card.Four, Suit: card.Spades}, {Rank: card.Ace, Suit: card.Spades}}, alice.Cards)
+	assert.Equal(t, []card.Card{{Rank: card.Three, Suit: card.Spades}, {Rank: card.Two, Suit: card.Spades}}, bob.Cards)
+}
+
+func TestDealInvalid(t *testing.T) {
+	testCases := []struct {
+		name        string
+		players     []string
+		cardsEach   int
+		closed      bool
+		expectedErr error
+	}{
+		{
+			name:        "not enough cards for every player",
+			players:     []string{"alice", "bob"},
+			cardsEach:   2,
+			expectedErr: ErrNotEnoughCards,
+		},
+		{
+			name:        "too many cards for the number of cards to be computed",
+			players:     []string{"alice", "bob"},
+			cardsEach:   math.MaxInt/2 + 2,
+			expectedErr: ErrNotEnoughCards,
+		},
+		{
+			name:        "closed deck",
+			players:     []string{"alice"},
+			cardsEach:   1,
+			closed:      true,
+			expectedErr: ErrDeckClosed,
+		},
+		{
+			name:      "no players",
+			players:   []string{},
+			cardsEach: 1,
+		},
+		{
+			name:      "player dealt twice",
+			players:   []string{"alice", "alice"},
+			cardsEach: 1,
+		},
+		{
+			name:      "empty player name",
+			players:   []string{"alice", ""},
+			cardsEach: 1,
+		},
+		{
+			name:      "no cards",
+			players:   []string{"alice"},
+			cardsEach: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deck, _ := NewPartialDeck([]string{"AS", "2S", "3S"})
+			deck.Closed = tc.closed
+
+			_, err := deck.Deal(tc.players, tc.cardsEach, true)
+			require.Error(t, err)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			}
+			assert.Equal(t, 3, deck.Remaining, "Nothing is dealt when the deal fails")
+			assert.Empty(t, deck.Piles)
+		})
+	}
+}
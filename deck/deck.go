@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"math/rand"
+	"time"
 )
 
 // Deck represents a deck of playing cards.
@@ -29,86 +30,284 @@ type Deck struct {
 	// Cards holds the card objects in the deck.
 	// Cards are specified in draw-order (the first one in the array will be drawn first).
 	Cards []card.Card
+	// Drawn holds the cards that were drawn from the deck and have not been placed in a pile.
+	Drawn []card.Card
+	// Piles holds the named piles (hands, discard piles, ...) attached to the deck, by name.
+	Piles map[string]*Pile
+	// DeckCount is the number of standard decks this deck is made of. A regular deck has a DeckCount of 1,
+	// a shoe (see NewShoe) may hold up to DeckCount copies of the same card.
+	DeckCount int
+	// Proof holds the commitment to the order of the deck, if it was shuffled with CommitShuffle.
+	// It must be kept secret until RevealProof allows it. It is discarded once the deck is reordered (see reordered).
+	Proof *Proof
+	// Closed indicates whether the deck has been closed. No cards can be drawn from a closed deck.
+	Closed bool
+	// AutoReshuffle indicates whether the deck refills itself with its discard pile (see DiscardPile), shuffled, when
+	// more cards are drawn than remaining.
+	AutoReshuffle bool
+	// OwnerTokenHash is the hash of the deck's owner token (see IssueOwnerToken). It is empty if the deck has no owner.
+	OwnerTokenHash string
+	// Owner is the name of the deck's owner (e.g. a player or a table), used to find the deck again. It is not secret:
+	// the owner token (see IssueOwnerToken) proves who owns the deck.
+	Owner string
+	// Metadata holds arbitrary data attached to the deck by its creator (e.g. the name of the game). It is not used by
+	// the deck itself.
+	Metadata map[string]string
+	// Version is incremented by the repository holding the deck (see DeckRepository) each time the deck is updated, so
+	// clients can tell whether the deck changed since they last saw it. A new deck has version 0.
+	Version uint64
+	// CreatedAt is when the deck was created.
+	CreatedAt time.Time
+	// ExpiresAt is when the deck expires, if it is not used until then. It is set by the repository holding the deck
+	// (see StoreConfig), and is the zero time if the deck never expires.
+	ExpiresAt time.Time
+	// History holds the events recorded by the operations on the deck, from its creation, in the order they happened
+	// (see Event). It is append-only: replaying it rebuilds the cards of the deck (see Replay). Once it grows too long,
+	// its oldest events are moved to the archive of the DeckRepository holding the deck (see EventCompacted).
+	History []Event
+
+	// shuffler is the source of randomness of the deck. When nil, a SecureShuffler is used.
+	shuffler Shuffler
+	// actor is who performs the operations on the deck, recorded in their events (see SetActor).
+	actor string
 }
 
-// NewStandardDeck creates a new Deck containing a full set of 52 standard playing cards.
-func NewStandardDeck() Deck {
-	cards := make([]card.Card, 0, 52)
+// Option configures a full Deck, as created by NewStandardDeck or NewShoe.
+type Option func(*Deck)
 
-	for _, s := range card.Suits() {
-		for _, r := range card.Ranks() {
-			cards = append(cards, card.Card{Rank: r, Suit: s})
+// WithJokers adds two Jokers (X1 and X2) per standard deck at the bottom of the Deck.
+func WithJokers() Option {
+	return func(d *Deck) {
+		for i := 0; i < d.DeckCount; i++ {
+			d.Cards = append(d.Cards, card.Jokers()...)
 		}
+		d.Remaining = len(d.Cards)
 	}
+}
 
-	return Deck{
+// NewStandardDeck creates a new Deck containing a full set of 52 standard playing cards.
+// Options may change the composition of the Deck (e.g. WithJokers).
+func NewStandardDeck(opts ...Option) Deck {
+	cards := standardCards()
+
+	d := Deck{
 		ID:        uuid.New(),
 		Shuffled:  false,
 		Remaining: len(cards),
 		Cards:     cards,
+		DeckCount: 1,
+		CreatedAt: creationTime(),
+	}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	d.record(Event{Type: EventCreated, Order: d.Cards})
+
+	return d
+}
+
+// creationTime returns the current time, to be recorded as the CreatedAt of a new Deck, or as the Time of an Event. It
+// is in UTC and has no monotonic clock reading, so a Deck is still equal to itself after being encoded and decoded
+// (e.g. by BoltStore).
+func creationTime() time.Time {
+	return time.Now().UTC().Round(0)
+}
+
+// standardCards returns the 52 standard playing cards, in order (by Suit, then by Rank).
+func standardCards() []card.Card {
+	cards := make([]card.Card, 0, 52)
+
+	for _, s := range card.Suits() {
+		for _, r := range card.Ranks() {
+			cards = append(cards, card.Card{Rank: r, Suit: s})
+		}
 	}
+
+	return cards
 }
 
 // NewPartialDeck creates a new Deck containing a custom set of cards based on the provided card codes.
 // It returns an error if any of these happens:
 // 1. The codes array is empty.
-// 2. There are any invalid codes in the codes array.
-// 3. There are repeated codes in the codes array.
+// 2. There are any invalid codes in the codes array (ErrInvalidCardCode).
+// 3. There are repeated codes in the codes array (ErrDuplicateCard).
 func NewPartialDeck(codes []string) (Deck, error) {
 	if len(codes) == 0 {
 		return Deck{}, errors.New("a deck must have at least one card")
 	}
 
-	cards := make([]card.Card, 0, len(codes))
-
-	for _, code := range codes {
-		c, err := card.FromString(code)
-		if err != nil {
-			return Deck{}, fmt.Errorf("invalid card code '%s': %w", code, err)
-		}
-		cards = append(cards, c)
+	cards, err := card.FromStrings(codes)
+	if err != nil {
+		return Deck{}, err
 	}
 
 	cardSet := make(map[string]bool)
 	for _, code := range codes {
 		if _, exists := cardSet[code]; exists {
-			return Deck{}, errors.New("repeated card code")
+			return Deck{}, fmt.Errorf("%w: card code %s is repeated", ErrDuplicateCard, code)
 		}
 		cardSet[code] = true
 	}
 
-	return Deck{
+	d := Deck{
 		ID:        uuid.New(),
 		Shuffled:  false,
 		Remaining: len(cards),
 		Cards:     cards,
-	}, nil
+		DeckCount: 1,
+		CreatedAt: creationTime(),
+	}
+	d.record(Event{Type: EventCreated, Order: d.Cards})
+
+	return d, nil
 }
 
-// Shuffle shuffles the cards in the Deck. Note that this mutates the Deck.
+// Clone returns a deep copy of the Deck: changes to the copy do not affect the Deck, and the other way around.
+// The Shuffler is shared, as it is a source of randomness rather than a part of the Deck's state, except for a
+// SeededShuffler, whose state decides the next shuffles of the Deck. The actor (see SetActor) is not copied.
+func (d *Deck) Clone() *Deck {
+	clone := *d
+	clone.Cards = cloneCards(d.Cards)
+	clone.Drawn = cloneCards(d.Drawn)
+	clone.actor = ""
+
+	// Events are never changed once recorded, so they can be shared.
+	if d.History != nil {
+		clone.History = make([]Event, len(d.History))
+		copy(clone.History, d.History)
+	}
+
+	if d.Piles != nil {
+		clone.Piles = make(map[string]*Pile, len(d.Piles))
+		for name, pile := range d.Piles {
+			clone.Piles[name] = &Pile{Name: pile.Name, Cards: cloneCards(pile.Cards)}
+		}
+	}
+
+	if d.Metadata != nil {
+		clone.Metadata = make(map[string]string, len(d.Metadata))
+		for key, value := range d.Metadata {
+			clone.Metadata[key] = value
+		}
+	}
+
+	if d.Proof != nil {
+		proof := *d.Proof
+		proof.InitialCards = cloneCards(d.Proof.InitialCards)
+		proof.Order = cloneCards(d.Proof.Order)
+		clone.Proof = &proof
+	}
+
+	// A SeededShuffler changes as it is used, so the clone needs its own.
+	if shuffler, ok := d.shuffler.(*SeededShuffler); ok {
+		clone.shuffler = shuffler.clone()
+	}
+
+	return &clone
+}
+
+// cloneCards returns a copy of cards. A nil slice stays nil.
+func cloneCards(cards []card.Card) []card.Card {
+	if cards == nil {
+		return nil
+	}
+	clone := make([]card.Card, len(cards))
+	copy(clone, cards)
+	return clone
+}
+
+// SetShuffler chooses the source of randomness used by the Deck from now on (e.g. NewSeededShuffler for
+// reproducible deals). By default, a Deck uses a SecureShuffler.
+func (d *Deck) SetShuffler(s Shuffler) {
+	d.shuffler = s
+}
+
+// Shuffler returns the source of randomness used by the Deck.
+func (d *Deck) Shuffler() Shuffler {
+	if d.shuffler == nil {
+		return SecureShuffler{}
+	}
+	return d.shuffler
+}
+
+// Seed returns the seed of the Deck's Shuffler, if it is a SeededShuffler.
+func (d *Deck) Seed() (int64, bool) {
+	if s, ok := d.shuffler.(*SeededShuffler); ok {
+		return s.source.seed, true
+	}
+	return 0, false
+}
+
+// Shuffle shuffles the cards in the Deck, using the Deck's Shuffler. Note that this mutates the Deck.
 // TODO: We may want to return a *new* deck here, and not mutate the caller.
 // There is no need to have shuffle functionality inside of creating the deck.
 // We can first create the deck, then shuffle it (if needed).
 func (d *Deck) Shuffle() {
-	// TODO: We probably want to set some secure seed here.
-	//       Do not let clients know how the deck is shuffled!
-	numberCards := len(d.Cards)
-	rand.Shuffle(numberCards, func(i, j int) {
+	d.ShuffleWith(d.Shuffler())
+}
+
+// ShuffleWithSource shuffles the cards in the Deck using the given source of randomness. Note that this mutates the
+// Deck. Shuffling the same cards with sources created from the same seed always results in the same order, which
+// makes deals reproducible (e.g. to replay a game, or to assert on the order of cards in tests).
+func (d *Deck) ShuffleWithSource(src rand.Source) {
+	d.ShuffleWith(rand.New(src))
+}
+
+// ShuffleWith shuffles the cards in the Deck using the given Shuffler, once. The Deck's Shuffler is not changed.
+func (d *Deck) ShuffleWith(s Shuffler) {
+	d.shuffleWith(s)
+	d.record(Event{Type: EventShuffled, Method: "uniform", Order: d.Cards})
+}
+
+// shuffleWith shuffles the Deck as ShuffleWith does, without recording an event.
+func (d *Deck) shuffleWith(s Shuffler) {
+	s.Shuffle(len(d.Cards), func(i, j int) {
 		d.Cards[i], d.Cards[j] = d.Cards[j], d.Cards[i]
 	})
 
 	d.Shuffled = true
+	d.reordered()
+}
+
+// Size returns the number of cards of the Deck, wherever they are: remaining to be drawn, drawn, or in a pile.
+func (d *Deck) Size() int {
+	size := len(d.Cards) + len(d.Drawn)
+	for _, pile := range d.Piles {
+		size += pile.Remaining()
+	}
+	return size
+}
+
+// Drawable returns the number of cards which can be drawn from the Deck: the remaining cards and, if the Deck has
+// AutoReshuffle, the cards of its discard pile.
+func (d *Deck) Drawable() int {
+	drawable := d.Remaining
+	if discardPile, exists := d.Piles[DiscardPile]; exists && d.AutoReshuffle {
+		drawable += discardPile.Remaining()
+	}
+	return drawable
 }
 
 // Draw removes and returns the specified number of cards from the top (the front) of the Deck.
-// It returns an error if there are not enough cards remaining in the Deck.
+// If the Deck has AutoReshuffle and there are not enough cards remaining, the Deck is first refilled with its discard
+// pile (see reshuffleDiscardPile).
+// It returns ErrNotEnoughCards if there are not enough cards to draw (see Drawable), or ErrDeckClosed if the Deck is
+// closed.
 func (d *Deck) Draw(count int) ([]card.Card, error) {
-	if count > d.Remaining {
-		return nil, fmt.Errorf("not enough cards remaining in the deck")
+	drawnCards, err := d.draw(count)
+	if err != nil {
+		return nil, err
 	}
 
-	if count <= 0 {
-		return nil, fmt.Errorf("draw count should be positive")
+	d.record(Event{Type: EventDrawn, Cards: drawnCards})
+	return drawnCards, nil
+}
+
+// draw draws the cards as Draw does, without recording an event, for the operations drawing cards on the way (e.g.
+// Deal).
+func (d *Deck) draw(count int) ([]card.Card, error) {
+	if err := d.prepareDraw(count); err != nil {
+		return nil, err
 	}
 
 	// We copy all drawn cards at once to avoid reallocating the array multiple times.
@@ -122,6 +321,121 @@ func (d *Deck) Draw(count int) ([]card.Card, error) {
 	// or handling removing in a different way.
 	d.Cards = d.Cards[count:]
 	d.Remaining -= count
+	d.Drawn = append(d.Drawn, drawnCards...)
 
 	return drawnCards, nil
 }
+
+// Return puts the given cards back at the bottom of the Deck, in the given order.
+// Each card must have been drawn from the Deck: it is taken from the drawn cards or, if it is not there, from one
+// of the Deck's piles.
+// It returns ErrCardNotDrawn if any of the cards did not come from the Deck. In that case, nothing is returned.
+func (d *Deck) Return(cards []card.Card) error {
+	if err := d.returnCards(cards); err != nil {
+		return err
+	}
+
+	d.record(Event{Type: EventReturned, Cards: cards})
+	return nil
+}
+
+// returnCards returns the cards as Return does, without recording an event.
+func (d *Deck) returnCards(cards []card.Card) error {
+	if len(cards) == 0 {
+		return errors.New("at least one card must be returned")
+	}
+
+	drawn := make([]card.Card, len(d.Drawn))
+	copy(drawn, d.Drawn)
+	piles := make(map[string][]card.Card, len(d.Piles))
+	for name, pile := range d.Piles {
+		piles[name] = append([]card.Card(nil), pile.Cards...)
+	}
+
+	for _, c := range cards {
+		if index := indexOf(drawn, c); index != -1 {
+			drawn = append(drawn[:index], drawn[index+1:]...)
+			continue
+		}
+
+		name, found := pileHolding(piles, c)
+		if !found {
+			return fmt.Errorf("%w: %s", ErrCardNotDrawn, c)
+		}
+		index := indexOf(piles[name], c)
+		piles[name] = append(piles[name][:index], piles[name][index+1:]...)
+	}
+
+	d.Drawn = drawn
+	for name, pileCards := range piles {
+		d.Piles[name].Cards = pileCards
+	}
+	d.putBack(cards)
+	return nil
+}
+
+// ReturnAll puts every drawn card, and every card in the Deck's piles, back at the bottom of the Deck.
+// The piles are removed from the Deck.
+func (d *Deck) ReturnAll() {
+	cards := d.returnAll()
+	d.record(Event{Type: EventReturnedAll, Cards: cards})
+}
+
+// returnAll returns the cards as ReturnAll does, without recording an event. It returns the returned cards.
+func (d *Deck) returnAll() []card.Card {
+	cards := cloneCards(d.Drawn)
+	for _, name := range d.pileNames() {
+		cards = append(cards, d.Piles[name].Cards...)
+	}
+
+	d.Drawn = nil
+	d.Piles = nil
+	d.putBack(cards)
+	return cards
+}
+
+// prepareDraw checks that count cards can be drawn from the Deck, and refills it with its discard pile if it needs to
+// (see Drawable).
+func (d *Deck) prepareDraw(count int) error {
+	if d.Closed {
+		return ErrDeckClosed
+	}
+
+	if count > d.Drawable() {
+		return fmt.Errorf("%w in the deck", ErrNotEnoughCards)
+	}
+
+	if count <= 0 {
+		return fmt.Errorf("draw count should be positive")
+	}
+
+	if count > d.Remaining {
+		d.reshuffleDiscardPile()
+	}
+	return nil
+}
+
+// reshuffleDiscardPile shuffles the cards of the discard pile, using the Deck's Shuffler, and puts them at the bottom
+// of the Deck. The discard pile is left empty.
+func (d *Deck) reshuffleDiscardPile() {
+	discardPile, exists := d.Piles[DiscardPile]
+	if !exists {
+		return
+	}
+
+	cards := discardPile.Cards
+	d.Shuffler().Shuffle(len(cards), func(i, j int) {
+		cards[i], cards[j] = cards[j], cards[i]
+	})
+	discardPile.Cards = nil
+	d.putBack(cards)
+	d.Shuffled = true
+	d.reordered()
+	d.record(Event{Type: EventReshuffled, Pile: DiscardPile, Order: d.Cards})
+}
+
+// putBack appends the cards to the bottom of the Deck.
+func (d *Deck) putBack(cards []card.Card) {
+	d.Cards = append(d.Cards, cards...)
+	d.Remaining += len(cards)
+}
@@ -4,6 +4,7 @@ import (
 	"deck-of-cards/card"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"math/rand"
 	"testing"
 )
 
@@ -30,6 +31,18 @@ func TestNewStandardDeck(t *testing.T) {
 	}
 }
 
+func TestNewStandardDeckWithJokers(t *testing.T) {
+	deck := NewStandardDeck(WithJokers())
+
+	assert.Equal(t, 54, deck.Remaining)
+	assert.Len(t, deck.Cards, 54)
+	assert.Equal(t, card.Jokers(), deck.Cards[52:], "Jokers are at the bottom of the deck")
+
+	shoe, err := NewShoe(2, WithJokers())
+	require.NoError(t, err)
+	assert.Equal(t, 2*54, shoe.Remaining, "A shoe has two Jokers per deck")
+}
+
 func TestNewPartialDeckCards(t *testing.T) {
 	codes := []string{"AS", "KD", "AC", "2C", "KH"}
 
@@ -64,6 +77,11 @@ func TestNewPartialDeckValid(t *testing.T) {
 			cardStrings: []string{"AS", "KD", "AC", "2C", "KH"},
 			wantDeckLen: 5,
 		},
+		{
+			name:        "cards with Jokers",
+			cardStrings: []string{"X1", "AS", "X2"},
+			wantDeckLen: 3,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -128,7 +146,7 @@ func TestShuffle(t *testing.T) {
 	}{
 		{
 			name:       "full deck",
-			createDeck: NewStandardDeck,
+			createDeck: func() Deck { return NewStandardDeck() },
 		},
 		{
 			name:       "partial deck",
@@ -154,6 +172,22 @@ func TestShuffle(t *testing.T) {
 	}
 }
 
+func TestShuffleWithSource(t *testing.T) {
+	deck1 := NewStandardDeck()
+	deck2 := NewStandardDeck()
+	deck3 := NewStandardDeck()
+
+	deck1.ShuffleWithSource(rand.NewSource(42))
+	deck2.ShuffleWithSource(rand.NewSource(42))
+	deck3.ShuffleWithSource(rand.NewSource(7))
+
+	assert.True(t, deck1.Shuffled, "deck is marked shuffled")
+	assert.Equal(t, deck1.Cards, deck2.Cards, "the same seed always yields the same order")
+	assert.ElementsMatch(t, NewStandardDeck().Cards, deck1.Cards, "the number of cards should remain the same after shuffling")
+	// There is a *very* small probability of two seeds yielding the same order.
+	assert.NotEqual(t, deck1.Cards, deck3.Cards, "different seeds yield different orders")
+}
+
 func TestDeckDraw(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -286,3 +320,79 @@ func TestPartialDeckDrawOrder(t *testing.T) {
 	}
 
 }
+
+func TestDeckReturn(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD", "AC", "2C", "KH"})
+	_, err := deck.Draw(2)
+	require.NoError(t, err)
+	_, err = deck.DrawToPile("discard", 2)
+	require.NoError(t, err)
+
+	// KD was drawn, AC is in the discard pile.
+	cards, _ := card.FromStrings([]string{"KD", "AC"})
+	err = deck.Return(cards)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, deck.Remaining)
+	assert.Len(t, deck.Cards, 3)
+	assert.Equal(t, "KD", deck.Cards[1].String(), "Returned cards go to the bottom of the deck")
+	assert.Equal(t, "AC", deck.Cards[2].String(), "Returned cards go to the bottom of the deck")
+	assert.Equal(t, "AS", deck.Drawn[0].String())
+	assert.Len(t, deck.Drawn, 1)
+	assert.Len(t, deck.Piles["discard"].Cards, 1)
+}
+
+func TestDeckReturnInvalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		cards []string
+	}{
+		{
+			name:  "no cards",
+			cards: []string{},
+		},
+		{
+			name:  "card still in the deck",
+			cards: []string{"KH"},
+		},
+		{
+			name:  "card not in the deck at all",
+			cards: []string{"9H"},
+		},
+		{
+			name:  "card returned twice",
+			cards: []string{"AS", "AS"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deck, _ := NewPartialDeck([]string{"AS", "KD", "KH"})
+			_, err := deck.Draw(2)
+			require.NoError(t, err)
+
+			cards, _ := card.FromStrings(tc.cards)
+			err = deck.Return(cards)
+
+			require.Error(t, err)
+			assert.Equal(t, 1, deck.Remaining, "A failed return does not change the deck")
+			assert.Len(t, deck.Drawn, 2, "A failed return does not change the drawn cards")
+		})
+	}
+}
+
+func TestDeckReturnAll(t *testing.T) {
+	deck := NewStandardDeck()
+	_, err := deck.Draw(5)
+	require.NoError(t, err)
+	_, err = deck.DrawToPile("alice", 2)
+	require.NoError(t, err)
+
+	deck.ReturnAll()
+
+	assert.Equal(t, 52, deck.Remaining)
+	assert.Len(t, deck.Cards, 52)
+	assert.Empty(t, deck.Drawn)
+	assert.Empty(t, deck.Piles)
+	assert.ElementsMatch(t, NewStandardDeck().Cards, deck.Cards, "Every card is back in the deck")
+}
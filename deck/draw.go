@@ -0,0 +1,123 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"errors"
+	"fmt"
+)
+
+// DrawBottom removes and returns the specified number of cards from the bottom (the back) of the Deck, one at a time:
+// the bottom card comes first.
+// It returns the same errors as Draw.
+func (d *Deck) DrawBottom(count int) ([]card.Card, error) {
+	if err := d.prepareDraw(count); err != nil {
+		return nil, err
+	}
+
+	drawnCards := make([]card.Card, count)
+	positions := make([]int, count)
+	for i := range drawnCards {
+		positions[i] = len(d.Cards) - 1 - i
+		drawnCards[i] = d.Cards[positions[i]]
+	}
+
+	d.Cards = d.Cards[:len(d.Cards)-count]
+	d.Remaining -= count
+	d.Drawn = append(d.Drawn, drawnCards...)
+	d.record(Event{Type: EventDrawn, Cards: drawnCards, Positions: positions})
+
+	return drawnCards, nil
+}
+
+// DrawAt removes and returns the card at the given position of the Deck, 0 being the top.
+// It returns ErrInvalidPosition if there is no card at that position, or ErrDeckClosed if the Deck is closed.
+func (d *Deck) DrawAt(position int) (card.Card, error) {
+	if d.Closed {
+		return card.Card{}, ErrDeckClosed
+	}
+
+	if position < 0 || position >= d.Remaining {
+		return card.Card{}, fmt.Errorf("%w: no card at position %d of %d", ErrInvalidPosition, position, d.Remaining)
+	}
+
+	drawnCard := d.Cards[position]
+	d.Cards = append(d.Cards[:position], d.Cards[position+1:]...)
+	d.Remaining--
+	d.Drawn = append(d.Drawn, drawnCard)
+	d.record(Event{Type: EventDrawn, Cards: []card.Card{drawnCard}, Positions: []int{position}})
+
+	return drawnCard, nil
+}
+
+// DrawCards removes and returns the cards with the given codes from the Deck, wherever they are, in the given order.
+// It returns ErrInvalidCardCode if a code is not valid, ErrCardNotInDeck if a card is not remaining in the Deck, or
+// ErrDeckClosed if the Deck is closed. In that case, nothing is drawn.
+func (d *Deck) DrawCards(codes []string) ([]card.Card, error) {
+	if d.Closed {
+		return nil, ErrDeckClosed
+	}
+
+	if len(codes) == 0 {
+		return nil, errors.New("at least one card must be drawn")
+	}
+
+	drawnCards, err := card.FromStrings(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := cloneCards(d.Cards)
+	positions := make([]int, len(drawnCards))
+	for i, c := range drawnCards {
+		positions[i] = indexOf(remaining, c)
+		if positions[i] == -1 {
+			return nil, fmt.Errorf("%w: %s", ErrCardNotInDeck, c)
+		}
+		remaining = append(remaining[:positions[i]], remaining[positions[i]+1:]...)
+	}
+
+	d.Cards = remaining
+	d.Remaining -= len(drawnCards)
+	d.Drawn = append(d.Drawn, drawnCards...)
+	d.record(Event{Type: EventDrawn, Cards: drawnCards, Positions: positions})
+
+	return drawnCards, nil
+}
+
+// DrawRandom removes and returns the specified number of cards, each picked at random among the remaining cards of
+// the Deck with the Deck's Shuffler.
+// It returns the same errors as Draw.
+func (d *Deck) DrawRandom(count int) ([]card.Card, error) {
+	if err := d.prepareDraw(count); err != nil {
+		return nil, err
+	}
+
+	shuffler := d.Shuffler()
+	drawnCards := make([]card.Card, count)
+	positions := make([]int, count)
+	for i := range drawnCards {
+		positions[i] = shuffler.Intn(len(d.Cards))
+		drawnCards[i] = d.Cards[positions[i]]
+		d.Cards = append(d.Cards[:positions[i]], d.Cards[positions[i]+1:]...)
+	}
+
+	d.Remaining -= count
+	d.Drawn = append(d.Drawn, drawnCards...)
+	d.record(Event{Type: EventDrawn, Cards: drawnCards, Positions: positions})
+
+	return drawnCards, nil
+}
+
+// Peek returns a copy of the specified number of cards from the top of the Deck, without drawing them.
+// It returns ErrNotEnoughCards if there are not enough cards remaining in the Deck.
+func (d *Deck) Peek(count int) ([]card.Card, error) {
+	if count > d.Remaining {
+		return nil, fmt.Errorf("%w in the deck", ErrNotEnoughCards)
+	}
+
+	if count <= 0 {
+		return nil, fmt.Errorf("peek count should be positive")
+	}
+
+	return cloneCards(d.Cards[:count]), nil
+}
@@ -0,0 +1,134 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDrawBottom(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD", "AC", "2C"})
+
+	drawnCards, err := deck.DrawBottom(2)
+	require.NoError(t, err)
+	assert.Equal(t, []card.Card{{Rank: card.Two, Suit: card.Clubs}, {Rank: card.Ace, Suit: card.Clubs}}, drawnCards,
+		"The bottom card is drawn first")
+	assert.Equal(t, 2, deck.Remaining)
+	assert.Equal(t, []card.Card{{Rank: card.Ace, Suit: card.Spades}, {Rank: card.King, Suit: card.Diamonds}}, deck.Cards)
+	assert.Equal(t, drawnCards, deck.Drawn)
+
+	_, err = deck.DrawBottom(3)
+	assert.ErrorIs(t, err, ErrNotEnoughCards)
+	_, err = deck.DrawBottom(0)
+	assert.Error(t, err)
+}
+
+func TestDrawAt(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD", "AC"})
+
+	drawnCard, err := deck.DrawAt(1)
+	require.NoError(t, err)
+	assert.Equal(t, card.Card{Rank: card.King, Suit: card.Diamonds}, drawnCard)
+	assert.Equal(t, 2, deck.Remaining)
+	assert.Equal(t, []card.Card{{Rank: card.Ace, Suit: card.Spades}, {Rank: card.Ace, Suit: card.Clubs}}, deck.Cards)
+	assert.Equal(t, []card.Card{drawnCard}, deck.Drawn)
+
+	for _, position := range []int{-1, 2} {
+		_, err = deck.DrawAt(position)
+		assert.ErrorIs(t, err, ErrInvalidPosition, "there is no card at position %d", position)
+	}
+
+	deck.Close()
+	_, err = deck.DrawAt(0)
+	assert.ErrorIs(t, err, ErrDeckClosed)
+}
+
+func TestDrawCards(t *testing.T) {
+	deck := NewStandardDeck()
+
+	drawnCards, err := deck.DrawCards([]string{"QH", "AS"})
+	require.NoError(t, err)
+	assert.Equal(t, []card.Card{{Rank: card.Queen, Suit: card.Hearts}, {Rank: card.Ace, Suit: card.Spades}}, drawnCards)
+	assert.Equal(t, 50, deck.Remaining)
+	assert.Len(t, deck.Cards, 50)
+	assert.NotContains(t, deck.Cards, drawnCards[0])
+	assert.NotContains(t, deck.Cards, drawnCards[1])
+
+	testCases := []struct {
+		name        string
+		codes       []string
+		expectedErr error
+	}{
+		{
+			name:        "card already drawn",
+			codes:       []string{"KD", "QH"},
+			expectedErr: ErrCardNotInDeck,
+		},
+		{
+			name:        "card repeated",
+			codes:       []string{"KD", "KD"},
+			expectedErr: ErrCardNotInDeck,
+		},
+		{
+			name:        "invalid card code",
+			codes:       []string{"KD", "ZZ"},
+			expectedErr: ErrInvalidCardCode,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := deck.DrawCards(tc.codes)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, 50, deck.Remaining, "Nothing is drawn when a card can not be drawn")
+		})
+	}
+}
+
+func TestDrawRandom(t *testing.T) {
+	deck := NewStandardDeck()
+	originalCards := append([]card.Card(nil), deck.Cards...)
+
+	drawnCards, err := deck.DrawRandom(10)
+	require.NoError(t, err)
+	assert.Len(t, drawnCards, 10)
+	assert.Equal(t, 42, deck.Remaining)
+	assert.ElementsMatch(t, originalCards, append(deck.Cards, drawnCards...), "Every card is either drawn or remaining")
+	// There is a *very* small probability of drawing the top 10 cards, in order.
+	assert.NotEqual(t, originalCards[:10], drawnCards)
+
+	// Seeded decks draw the same random cards.
+	deck1 := NewStandardDeck()
+	deck2 := NewStandardDeck()
+	deck1.SetShuffler(NewSeededShuffler(42))
+	deck2.SetShuffler(NewSeededShuffler(42))
+	drawnCards1, err := deck1.DrawRandom(5)
+	require.NoError(t, err)
+	drawnCards2, err := deck2.DrawRandom(5)
+	require.NoError(t, err)
+	assert.Equal(t, drawnCards1, drawnCards2)
+
+	_, err = deck1.DrawRandom(48)
+	assert.ErrorIs(t, err, ErrNotEnoughCards)
+}
+
+func TestPeek(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD", "AC"})
+
+	peekedCards, err := deck.Peek(2)
+	require.NoError(t, err)
+	assert.Equal(t, []card.Card{{Rank: card.Ace, Suit: card.Spades}, {Rank: card.King, Suit: card.Diamonds}}, peekedCards)
+	assert.Equal(t, 3, deck.Remaining, "Peeking does not draw the cards")
+
+	// The peeked cards are a copy.
+	peekedCards[0] = card.Card{Rank: card.Two, Suit: card.Clubs}
+	drawnCards, err := deck.Draw(1)
+	require.NoError(t, err)
+	assert.Equal(t, []card.Card{{Rank: card.Ace, Suit: card.Spades}}, drawnCards)
+
+	_, err = deck.Peek(3)
+	assert.ErrorIs(t, err, ErrNotEnoughCards)
+	_, err = deck.Peek(0)
+	assert.Error(t, err)
+}
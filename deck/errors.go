@@ -0,0 +1,41 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"errors"
+)
+
+// Errors returned by the operations of a Deck. They are wrapped with more details (e.g. which card is invalid), so
+// they must be checked with errors.Is.
+var (
+	// ErrNotEnoughCards is returned when drawing more cards than remaining in a Deck or a Pile.
+	ErrNotEnoughCards = errors.New("not enough cards remaining")
+	// ErrInvalidCardCode is returned when a card code is not valid. It is card.ErrInvalidCode, so errors of the card
+	// package match it too.
+	ErrInvalidCardCode = card.ErrInvalidCode
+	// ErrDuplicateCard is returned when a card is repeated more than a Deck allows (once, or once per standard deck
+	// for a shoe).
+	ErrDuplicateCard = errors.New("duplicate card")
+	// ErrDeckClosed is returned when drawing from a closed Deck.
+	ErrDeckClosed = errors.New("deck is closed")
+	// ErrPileNotFound is returned when a Deck has no pile with the given name.
+	ErrPileNotFound = errors.New("pile not found")
+	// ErrCardNotDrawn is returned when a card is expected to have been drawn from a Deck, but was not.
+	ErrCardNotDrawn = errors.New("card has not been drawn from the deck")
+	// ErrCardNotInDeck is returned when a card is expected to be remaining in a Deck, but is not.
+	ErrCardNotInDeck = errors.New("card is not in the deck")
+	// ErrInvalidPosition is returned when a position is outside of the remaining cards of a Deck.
+	ErrInvalidPosition = errors.New("position is outside of the deck")
+	// ErrProofUnavailable is returned when the Proof of a Deck can not be revealed.
+	ErrProofUnavailable = errors.New("proof is not available")
+	// ErrNothingToUndo is returned when undoing more operations than a Deck went through (see Undo).
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrNothingToRedo is returned when redoing more operations than were undone (see Redo).
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrOrderRevealed is returned when undoing an operation after which the order of a Deck could be revealed (see
+	// Undo).
+	ErrOrderRevealed = errors.New("the order of the deck may have been revealed")
+	// ErrInvalidHistory is returned when the History of a Deck can not be replayed, or does not lead to the state of
+	// the Deck.
+	ErrInvalidHistory = errors.New("invalid deck history")
+)
@@ -0,0 +1,93 @@
+package deck
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		operation   func() error
+		expectedErr error
+	}{
+		{
+			name: "drawing more cards than remaining",
+			operation: func() error {
+				deck := NewStandardDeck()
+				_, err := deck.Draw(53)
+				return err
+			},
+			expectedErr: ErrNotEnoughCards,
+		},
+		{
+			name: "invalid card code",
+			operation: func() error {
+				_, err := NewPartialDeck([]string{"AS", "ZZ"})
+				return err
+			},
+			expectedErr: ErrInvalidCardCode,
+		},
+		{
+			name: "repeated card code",
+			operation: func() error {
+				_, err := NewPartialDeck([]string{"AS", "AS"})
+				return err
+			},
+			expectedErr: ErrDuplicateCard,
+		},
+		{
+			name: "card repeated more than the deck count",
+			operation: func() error {
+				_, err := NewPartialShoe([]string{"AS", "AS", "AS"}, 2)
+				return err
+			},
+			expectedErr: ErrDuplicateCard,
+		},
+		{
+			name: "drawing from a closed deck",
+			operation: func() error {
+				deck := NewStandardDeck()
+				deck.Close()
+				_, err := deck.Draw(1)
+				return err
+			},
+			expectedErr: ErrDeckClosed,
+		},
+		{
+			name: "unknown pile",
+			operation: func() error {
+				deck := NewStandardDeck()
+				_, err := deck.DrawFromPile("unknown", 1)
+				return err
+			},
+			expectedErr: ErrPileNotFound,
+		},
+		{
+			name: "returning a card that was not drawn",
+			operation: func() error {
+				deck := NewStandardDeck()
+				return deck.Return(deck.Cards[:1])
+			},
+			expectedErr: ErrCardNotDrawn,
+		},
+		{
+			name: "revealing the proof of a deck with remaining cards",
+			operation: func() error {
+				deck := NewStandardDeck()
+				_ = deck.CommitShuffle("")
+				_, err := deck.RevealProof()
+				return err
+			},
+			expectedErr: ErrProofUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.operation()
+			assert.True(t, errors.Is(err, tc.expectedErr), "expected %v, got %v", tc.expectedErr, err)
+		})
+	}
+}
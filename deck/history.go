@@ -0,0 +1,385 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EventType tells which operation an Event records.
+type EventType string
+
+const (
+	// EventCreated records the creation of the Deck. Order holds its cards.
+	EventCreated EventType = "created"
+	// EventShuffled records a shuffle of the Deck (see Shuffle, RiffleShuffle, ...). Method tells which one.
+	EventShuffled EventType = "shuffled"
+	// EventCut records a cut of the Deck (see Cut).
+	EventCut EventType = "cut"
+	// EventReshuffled records the Deck being refilled with its discard pile, shuffled (see AutoReshuffle).
+	EventReshuffled EventType = "reshuffled"
+	// EventDrawn records cards drawn from the Deck, wherever they were in the Deck. If Pile is not empty, they were
+	// placed directly on that pile (see DrawToPile).
+	EventDrawn EventType = "drawn"
+	// EventDealt records the cards dealt to a player, whose pile is Pile (see Deal).
+	EventDealt EventType = "dealt"
+	// EventReturned records drawn cards put back at the bottom of the Deck (see Return).
+	EventReturned EventType = "returned"
+	// EventReturnedAll records every drawn card put back at the bottom of the Deck, and its piles removed (see
+	// ReturnAll).
+	EventReturnedAll EventType = "returned_all"
+	// EventInserted records cards inserted in the Deck at Position (see Insert).
+	EventInserted EventType = "inserted"
+	// EventAddedToPile records drawn cards moved to Pile (see AddToPile).
+	EventAddedToPile EventType = "added_to_pile"
+	// EventDrawnFromPile records cards drawn from the top of Pile (see DrawFromPile).
+	EventDrawnFromPile EventType = "drawn_from_pile"
+	// EventPileShuffled records a shuffle of Pile (see ShufflePile).
+	EventPileShuffled EventType = "pile_shuffled"
+	// EventClosed records the Deck being closed (see Close).
+	EventClosed EventType = "closed"
+	// EventUndone records the operations of Versions being undone, from the most recent one (see Undo).
+	EventUndone EventType = "undone"
+	// EventRedone records the operations of Versions being redone, from the most recently undone one (see Redo).
+	EventRedone EventType = "redone"
+	// EventCompacted stands for the oldest events of the History, once they are moved to the archive of the
+	// DeckRepository holding the Deck because the History grew too long (see maxHistory and
+	// DeckRepository.ArchivedHistory). Snapshot holds the state of the Deck they led to, and Order its remaining cards.
+	// It has the Sequence of the last archived event.
+	EventCompacted EventType = "compacted"
+)
+
+// maxHistory is the maximum number of events in the History of a Deck once it is updated by a DeckRepository. Beyond
+// it, the oldest events are archived (see compactHistory), so the History of a Deck which is played for a long time
+// (or shuffled over and over) does not grow without bounds, and neither does the cost of updating it.
+const maxHistory = 1000
+
+// Event records an operation on a Deck. The events of a Deck are kept in its History, in the order they happened, and
+// are never changed nor deleted once recorded: the oldest ones are only moved to an archive (see EventCompacted).
+// An Event holds enough details to be replayed (see Replay): the outcome of random operations, such as shuffles, is
+// recorded in Order.
+type Event struct {
+	// Sequence is the number of the Event in the History of its Deck, starting at 1. It does not change when the
+	// events before it are archived (see EventCompacted).
+	Sequence int
+	// Version is the Version of the Deck right after the operation. The events recorded by the same update of a
+	// DeckRepository share the same Version, and make up one operation (see Undo). The events recorded before the
+	// Deck was added to a DeckRepository, such as its creation, have version 0.
+	Version uint64
+	Type    EventType
+	// Time is when the operation happened.
+	Time time.Time
+	// Actor is who performed the operation (see SetActor). It is empty if nobody was named.
+	Actor string
+	// Cards holds the cards drawn, dealt, returned, inserted, or moved to or from a pile, in order.
+	Cards []card.Card
+	// Positions holds the position in the Deck (0 being the top) of each of the Cards drawn, for EventDrawn, counted
+	// once the previous cards are drawn. It is empty if the cards were drawn from the top. A shoe may hold the same
+	// card more than once, so the cards alone do not tell which ones were drawn.
+	Positions []int
+	// Pile is the name of the pile the cards were moved to or from, or which was shuffled.
+	Pile string
+	// Method is how the Deck was shuffled, for EventShuffled (e.g. "uniform" or "riffle").
+	Method string
+	// Committed tells whether the Deck was shuffled with a commitment to its order, for EventShuffled (see
+	// CommitShuffle).
+	Committed bool
+	// Position is where the cards were inserted, for EventInserted.
+	Position Position
+	// Order holds the remaining cards of the Deck (or the cards of Pile, for EventPileShuffled) right after the
+	// operation, for the operations which change their order. Like the Deck's Cards, it tells which cards are coming
+	// next, so it must be kept secret.
+	Order []card.Card
+	// Versions holds the versions of the operations undone or redone, for EventUndone and EventRedone.
+	Versions []uint64
+	// Snapshot holds the state of the Deck the archived events led to, for EventCompacted.
+	Snapshot *Snapshot
+}
+
+// Snapshot is the state of a Deck, apart from its remaining cards, as recorded by EventCompacted.
+type Snapshot struct {
+	Drawn    []card.Card
+	Piles    map[string][]card.Card
+	Shuffled bool
+	Closed   bool
+	// Committed tells whether the Deck was shuffled with a commitment before (see Event.Committed).
+	Committed bool
+}
+
+// SetActor sets who performs the next operations on the Deck (e.g. "owner"), recorded in their events (see History).
+// The events recorded before are not changed.
+// The actor is not saved with the Deck: it must be set again when the Deck is retrieved from a DeckRepository.
+func (d *Deck) SetActor(actor string) {
+	d.actor = actor
+}
+
+// record appends the event to the History of the Deck, at the current time and performed by the Deck's actor.
+func (d *Deck) record(event Event) {
+	event.Sequence = 1
+	if len(d.History) > 0 {
+		event.Sequence = d.History[len(d.History)-1].Sequence + 1
+	}
+	event.Version = d.Version
+	event.Time = creationTime()
+	event.Actor = d.actor
+	event.Cards = cloneCards(event.Cards)
+	event.Order = cloneCards(event.Order)
+	if event.Positions != nil {
+		event.Positions = append([]int(nil), event.Positions...)
+	}
+	d.History = append(d.History, event)
+}
+
+// Replay rebuilds the Deck from its History: it returns a copy of the Deck whose cards (remaining, drawn, and in
+// piles), and whether it is shuffled and closed, only come from replaying the events, from the creation of the Deck
+// (or from the state its archived events led to, see EventCompacted).
+// The events of undone operations (see Undo) are skipped.
+// The other fields (e.g. the ID, the Owner or the Proof) are copied from the Deck.
+// It returns ErrInvalidHistory if an event can not be replayed.
+func (d *Deck) Replay() (*Deck, error) {
+	ops, err := d.operations()
+	if err != nil {
+		return nil, err
+	}
+	return d.replay(d.History, ops.reverted, nil)
+}
+
+// replay rebuilds the Deck from the given events, as Replay does, skipping the events of the reverted operations. If
+// visit is not nil, it is called with each replayed event and the Deck right after it.
+func (d *Deck) replay(events []Event, reverted map[uint64]bool, visit func(event Event, replayed *Deck)) (*Deck, error) {
+	if len(events) == 0 || (events[0].Type != EventCreated && events[0].Type != EventCompacted) {
+		return nil, fmt.Errorf("%w: the history does not start with the creation of the deck", ErrInvalidHistory)
+	}
+
+	replayed := d.Clone()
+	replayed.Cards = nil
+	replayed.Drawn = nil
+	replayed.Piles = nil
+	replayed.Remaining = 0
+	replayed.Shuffled = false
+	replayed.Closed = false
+	for i, event := range events {
+		if (event.Type == EventCreated || event.Type == EventCompacted) && i > 0 {
+			return nil, fmt.Errorf("%w: event %d: the deck can only be created once", ErrInvalidHistory, event.Sequence)
+		}
+		if reverted[event.Version] {
+			continue
+		}
+		if err := replayed.apply(event); err != nil {
+			return nil, fmt.Errorf("%w: event %d (%s): %v", ErrInvalidHistory, event.Sequence, event.Type, err)
+		}
+		if visit != nil {
+			visit(event, replayed)
+		}
+	}
+	return replayed, nil
+}
+
+// VerifyHistory checks that replaying the History of the Deck (see Replay) leads to the current state of the Deck, so
+// the History is a complete record of what happened to the Deck. It returns ErrInvalidHistory if it does not.
+func (d *Deck) VerifyHistory() error {
+	replayed, err := d.Replay()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !sameCards(replayed.Cards, d.Cards) || replayed.Remaining != d.Remaining:
+		return fmt.Errorf("%w: the remaining cards do not match", ErrInvalidHistory)
+	case !sameCards(replayed.Drawn, d.Drawn):
+		return fmt.Errorf("%w: the drawn cards do not match", ErrInvalidHistory)
+	case replayed.Shuffled != d.Shuffled || replayed.Closed != d.Closed:
+		return fmt.Errorf("%w: the state of the deck does not match", ErrInvalidHistory)
+	case len(replayed.Piles) != len(d.Piles):
+		return fmt.Errorf("%w: the piles do not match", ErrInvalidHistory)
+	}
+	for name, pile := range d.Piles {
+		replayedPile, exists := replayed.Piles[name]
+		if !exists || !sameCards(replayedPile.Cards, pile.Cards) {
+			return fmt.Errorf("%w: pile '%s' does not match", ErrInvalidHistory, name)
+		}
+	}
+	return nil
+}
+
+// apply replays the event on the Deck.
+func (d *Deck) apply(event Event) error {
+	switch event.Type {
+	case EventCreated:
+		d.putBack(cloneCards(event.Order))
+	case EventShuffled:
+		d.Shuffled = true
+		return reorder(&d.Cards, event.Order)
+	case EventCut:
+		return reorder(&d.Cards, event.Order)
+	case EventReshuffled:
+		discardPile, err := d.Pile(DiscardPile)
+		if err != nil {
+			return err
+		}
+		d.putBack(discardPile.Cards)
+		discardPile.Cards = nil
+		d.Shuffled = true
+		return reorder(&d.Cards, event.Order)
+	case EventDrawn, EventDealt:
+		remaining, err := removeCardsAt(d.Cards, event.Cards, event.Positions)
+		if err != nil {
+			return err
+		}
+		d.Cards = remaining
+		d.Remaining -= len(event.Cards)
+		if event.Pile == "" {
+			d.Drawn = append(d.Drawn, event.Cards...)
+			return nil
+		}
+		pile, err := d.pileOrCreate(event.Pile)
+		if err != nil {
+			return err
+		}
+		pile.add(event.Cards)
+	case EventReturned:
+		return d.returnCards(event.Cards)
+	case EventReturnedAll:
+		d.returnAll()
+	case EventInserted:
+		if err := d.insert(event.Cards, PositionBottom); err != nil {
+			return err
+		}
+		return reorder(&d.Cards, event.Order)
+	case EventAddedToPile:
+		return d.addToPile(event.Pile, event.Cards)
+	case EventDrawnFromPile:
+		pile, err := d.Pile(event.Pile)
+		if err != nil {
+			return err
+		}
+		drawnCards, err := pile.draw(len(event.Cards))
+		if err != nil {
+			return err
+		}
+		if !sameCards(drawnCards, event.Cards) {
+			return errors.New("the drawn cards are not on top of the pile")
+		}
+		d.Drawn = append(d.Drawn, drawnCards...)
+	case EventPileShuffled:
+		pile, err := d.Pile(event.Pile)
+		if err != nil {
+			return err
+		}
+		return reorder(&pile.Cards, event.Order)
+	case EventClosed:
+		d.Closed = true
+	case EventCompacted:
+		if event.Snapshot == nil {
+			return errors.New("the state of the deck is missing")
+		}
+		d.putBack(cloneCards(event.Order))
+		d.Drawn = cloneCards(event.Snapshot.Drawn)
+		for name, cards := range event.Snapshot.Piles {
+			pile, err := d.pileOrCreate(name)
+			if err != nil {
+				return err
+			}
+			pile.Cards = cloneCards(cards)
+		}
+		d.Shuffled = event.Snapshot.Shuffled
+		d.Closed = event.Snapshot.Closed
+	case EventUndone, EventRedone:
+		// Replay skips the events of undone operations.
+	default:
+		return fmt.Errorf("unknown event type '%s'", event.Type)
+	}
+	return nil
+}
+
+// compactHistory keeps the History of the Deck under maxHistory events: beyond it, the oldest events are moved out of
+// the History, and replaced by an EventCompacted event holding the state of the Deck they led to, so about half of the
+// events are left. Events are only moved by whole operations (see Event.Version), and the moved operations can not be
+// undone anymore.
+// It returns the moved events, in order, for the DeckRepository holding the Deck to archive them (see
+// DeckRepository.ArchivedHistory). If the History can not be replayed, it is left as it is, and nothing is returned.
+func (d *Deck) compactHistory() []Event {
+	if len(d.History) <= maxHistory {
+		return nil
+	}
+
+	compacted := len(d.History) - maxHistory/2
+	for compacted > 0 && d.History[compacted].Version == d.History[compacted-1].Version {
+		compacted--
+	}
+	if compacted <= 1 {
+		return nil
+	}
+
+	ops, err := d.operations()
+	if err != nil {
+		return nil
+	}
+	// The compacted operations stay as they are now: operations which are undone are left out of the state.
+	committed := false
+	state, err := d.replay(d.History[:compacted], ops.reverted, func(event Event, _ *Deck) {
+		committed = committed || event.committed()
+	})
+	if err != nil {
+		return nil
+	}
+
+	// The EventCompacted of a previous compaction only stands for events which are already archived.
+	var archived []Event
+	for _, event := range d.History[:compacted] {
+		if event.Type != EventCompacted {
+			archived = append(archived, event)
+		}
+	}
+
+	last := d.History[compacted-1]
+	snapshot := &Snapshot{Drawn: state.Drawn, Shuffled: state.Shuffled, Closed: state.Closed, Committed: committed}
+	if len(state.Piles) > 0 {
+		snapshot.Piles = make(map[string][]card.Card, len(state.Piles))
+		for name, pile := range state.Piles {
+			snapshot.Piles[name] = pile.Cards
+		}
+	}
+	event := Event{
+		Type:     EventCompacted,
+		Sequence: last.Sequence,
+		Version:  last.Version,
+		Time:     last.Time,
+		Order:    state.Cards,
+		Snapshot: snapshot,
+	}
+	d.History = append([]Event{event}, d.History[compacted:]...)
+	return archived
+}
+
+// committed tells whether the Deck was shuffled with a commitment by the event, or before the events compacted by it.
+func (e Event) committed() bool {
+	return e.Committed || (e.Snapshot != nil && e.Snapshot.Committed)
+}
+
+// reorder replaces the cards with order, which must hold the same cards.
+func reorder(cards *[]card.Card, order []card.Card) error {
+	if len(order) != len(*cards) {
+		return errors.New("the order does not have the same cards")
+	}
+	if _, err := removeCards(*cards, order, ErrCardNotInDeck); err != nil {
+		return fmt.Errorf("the order does not have the same cards: %w", err)
+	}
+
+	*cards = cloneCards(order)
+	return nil
+}
+
+// sameCards tells whether a and b hold the same cards, in the same order. Nil and empty slices are the same.
+func sameCards(a, b []card.Card) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,298 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD", "AC", "2C"})
+	deck.SetActor("dealer")
+	deck.Shuffle()
+	deck.SetActor("alice")
+	drawnCards, err := deck.Draw(2)
+	require.NoError(t, err)
+	require.NoError(t, deck.Return(drawnCards[:1]))
+
+	expectedTypes := []EventType{EventCreated, EventShuffled, EventDrawn, EventReturned}
+	require.Len(t, deck.History, len(expectedTypes))
+	for i, event := range deck.History {
+		assert.Equal(t, i+1, event.Sequence)
+		assert.Equal(t, expectedTypes[i], event.Type)
+		assert.False(t, event.Time.IsZero())
+	}
+
+	assert.Empty(t, deck.History[0].Actor, "The events recorded before an actor is set are not attributed")
+	assert.Equal(t, "dealer", deck.History[1].Actor)
+	assert.Equal(t, "alice", deck.History[2].Actor)
+	assert.Equal(t, "uniform", deck.History[1].Method)
+	assert.Equal(t, drawnCards, deck.History[2].Cards)
+	assert.Equal(t, drawnCards[:1], deck.History[3].Cards)
+
+	// Events keep their own copy of the cards.
+	drawnCards[0] = card.Card{Rank: card.Joker, Suit: card.Red}
+	assert.NotEqual(t, drawnCards[0], deck.History[2].Cards[0])
+}
+
+func TestHistoryFailedOperations(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD"})
+
+	_, err := deck.Draw(3)
+	require.Error(t, err)
+	err = deck.Return([]card.Card{{Rank: card.Ace, Suit: card.Spades}})
+	require.Error(t, err)
+
+	assert.Len(t, deck.History, 1, "Failed operations are not recorded")
+}
+
+func TestReplay(t *testing.T) {
+	testCases := []struct {
+		name      string
+		operation func(t *testing.T, d *Deck)
+	}{
+		{
+			name: "draws",
+			operation: func(t *testing.T, d *Deck) {
+				_, err := d.DrawCards([]string{"KH"})
+				require.NoError(t, err)
+				_, err = d.Draw(2)
+				require.NoError(t, err)
+				_, err = d.DrawBottom(1)
+				require.NoError(t, err)
+				_, err = d.DrawAt(3)
+				require.NoError(t, err)
+				_, err = d.DrawRandom(2)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "shuffles",
+			operation: func(t *testing.T, d *Deck) {
+				d.Shuffle()
+				d.RiffleShuffle()
+				d.OverhandShuffle()
+				require.NoError(t, d.PileShuffle(3))
+				require.NoError(t, d.PerfectFaro(true))
+				require.NoError(t, d.Cut(10))
+			},
+		},
+		{
+			name: "returns and insertions",
+			operation: func(t *testing.T, d *Deck) {
+				drawnCards, err := d.Draw(5)
+				require.NoError(t, err)
+				require.NoError(t, d.Return(drawnCards[1:3]))
+				require.NoError(t, d.Insert(drawnCards[3:], PositionRandom))
+				require.NoError(t, d.Insert(drawnCards[:1], 4))
+				_, err = d.DrawToPile("alice", 2)
+				require.NoError(t, err)
+				d.ReturnAll()
+			},
+		},
+		{
+			name: "piles",
+			operation: func(t *testing.T, d *Deck) {
+				drawnCards, err := d.Draw(3)
+				require.NoError(t, err)
+				require.NoError(t, d.AddToPile("bob", drawnCards))
+				_, err = d.DrawToPile("bob", 2)
+				require.NoError(t, err)
+				require.NoError(t, d.ShufflePile("bob"))
+				_, err = d.DrawFromPile("bob", 1)
+				require.NoError(t, err)
+				_, err = d.Deal([]string{"alice", "bob", "carol"}, 3, true)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "auto-reshuffle",
+			operation: func(t *testing.T, d *Deck) {
+				d.AutoReshuffle = true
+				_, err := d.DrawToPile(DiscardPile, 50)
+				require.NoError(t, err)
+				_, err = d.Draw(10)
+				require.NoError(t, err)
+				d.Close()
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deck := NewStandardDeck()
+			deck.SetShuffler(NewSeededShuffler(7))
+			tc.operation(t, &deck)
+
+			replayed, err := deck.Replay()
+			require.NoError(t, err)
+			assert.Equal(t, deck.Cards, replayed.Cards)
+			assert.Equal(t, deck.Remaining, replayed.Remaining)
+			assert.ElementsMatch(t, deck.Drawn, replayed.Drawn)
+			assert.Equal(t, deck.Shuffled, replayed.Shuffled)
+			assert.Equal(t, deck.Closed, replayed.Closed)
+			assert.NoError(t, deck.VerifyHistory())
+		})
+	}
+}
+
+func TestReplayShoe(t *testing.T) {
+	// A shoe may hold the same card more than once: replaying a draw takes the copy at the position it was drawn from.
+	testCases := []struct {
+		name string
+		draw func(d *Deck) error
+	}{
+		{
+			name: "from the bottom",
+			draw: func(d *Deck) error {
+				_, err := d.DrawBottom(1)
+				return err
+			},
+		},
+		{
+			name: "at a position",
+			draw: func(d *Deck) error {
+				_, err := d.DrawAt(2)
+				return err
+			},
+		},
+		{
+			name: "at random",
+			draw: func(d *Deck) error {
+				_, err := d.DrawRandom(2)
+				return err
+			},
+		},
+		{
+			name: "listed cards",
+			draw: func(d *Deck) error {
+				_, err := d.DrawCards([]string{"AS", "AS"})
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deck, err := NewPartialShoe([]string{"AS", "KD", "AS"}, 2)
+			require.NoError(t, err)
+			deck.SetShuffler(NewSeededShuffler(3))
+			require.NoError(t, tc.draw(&deck))
+
+			replayed, err := deck.Replay()
+			require.NoError(t, err)
+			assert.Equal(t, deck.Cards, replayed.Cards)
+			assert.NoError(t, deck.VerifyHistory())
+		})
+	}
+}
+
+func TestVerifyHistoryInvalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		tamper func(d *Deck)
+	}{
+		{
+			name: "event missing",
+			tamper: func(d *Deck) {
+				d.History = d.History[:len(d.History)-1]
+			},
+		},
+		{
+			name: "cards changed",
+			tamper: func(d *Deck) {
+				d.History[2].Cards = []card.Card{{Rank: card.Two, Suit: card.Clubs}}
+			},
+		},
+		{
+			name: "order with other cards",
+			tamper: func(d *Deck) {
+				d.History[1].Order[0] = card.Card{Rank: card.Joker, Suit: card.Red}
+			},
+		},
+		{
+			name: "no creation",
+			tamper: func(d *Deck) {
+				d.History = d.History[1:]
+			},
+		},
+		{
+			name: "unknown event",
+			tamper: func(d *Deck) {
+				d.History[1].Type = "teleported"
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deck, _ := NewPartialDeck([]string{"AS", "KD", "AC"})
+			deck.Shuffle()
+			_, err := deck.Draw(1)
+			require.NoError(t, err)
+
+			tc.tamper(&deck)
+			assert.ErrorIs(t, deck.VerifyHistory(), ErrInvalidHistory)
+		})
+	}
+}
+
+func TestCompactHistory(t *testing.T) {
+	store, deck := newStoredDeck(t)
+	update(t, store, deck, func(d *Deck) error {
+		_, err := d.DrawToPile("alice", 2)
+		return err
+	})
+	update(t, store, deck, func(d *Deck) error {
+		_, err := d.Draw(3)
+		return err
+	})
+	// The draw is undone, and stays undone once compacted.
+	update(t, store, deck, func(d *Deck) error {
+		_, err := d.Undo(1)
+		return err
+	})
+
+	var updated *Deck
+	for i := 0; i < maxHistory; i++ {
+		updated = update(t, store, deck, func(d *Deck) error {
+			d.RiffleShuffle()
+			return d.Cut(1)
+		})
+	}
+
+	history := updated.History
+	assert.LessOrEqual(t, len(history), maxHistory, "The history does not grow without bounds")
+	assert.Equal(t, EventCompacted, history[0].Type)
+	assert.Equal(t, history[0].Sequence+1, history[1].Sequence, "Events keep their sequence")
+	assert.Equal(t, 2*maxHistory+5, history[len(history)-1].Sequence)
+	require.NotNil(t, history[0].Snapshot)
+	assert.Len(t, history[0].Snapshot.Piles["alice"], 2)
+	assert.Empty(t, history[0].Snapshot.Drawn)
+	assert.Equal(t, 50, updated.Remaining)
+	assert.NoError(t, updated.VerifyHistory())
+
+	// The events are not lost: they are archived by the store.
+	archived, err := store.ArchivedHistory(deck.ID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, archived, history[0].Sequence)
+	for _, event := range archived {
+		assert.NotEqual(t, EventCompacted, event.Type)
+	}
+	assert.Equal(t, EventCreated, archived[0].Type)
+	assert.Equal(t, EventUndone, archived[4].Type)
+
+	// The recent operations can still be undone, but not the compacted ones.
+	beforeShuffle := updated.Clone()
+	undone := update(t, store, deck, func(d *Deck) error {
+		_, err := d.Undo(1)
+		return err
+	})
+	assert.NotEqual(t, beforeShuffle.Cards, undone.Cards)
+	err = store.Update(deck.ID, func(d *Deck) error {
+		_, err := d.Undo(len(d.History))
+		return err
+	})
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+}
@@ -0,0 +1,133 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"errors"
+	"fmt"
+)
+
+// Position is where cards are inserted in a Deck (see Insert): the index of the first inserted card from the top of
+// the Deck (0 being the top), PositionBottom, or PositionRandom.
+type Position int
+
+const (
+	// PositionTop inserts the cards on top of the Deck: they are the next ones to be drawn.
+	PositionTop Position = 0
+	// PositionBottom inserts the cards at the bottom of the Deck.
+	PositionBottom Position = -1
+	// PositionRandom inserts each card at its own random position in the Deck, picked with the Deck's Shuffler.
+	PositionRandom Position = -2
+)
+
+// Insert puts the given cards in the Deck at the given position, in the given order.
+// Cards which were drawn from the Deck (including the cards in its piles, as for Return) are put back. Other cards
+// are added to the Deck, as long as the Deck does not end up with more copies of a card than it allows: one, or one
+// per standard deck for a shoe (as for NewPartialDeck and NewPartialShoe).
+// It returns ErrInvalidPosition if the position is outside of the Deck, or ErrDuplicateCard if a card can not be
+// added. In that case, nothing is inserted.
+func (d *Deck) Insert(cards []card.Card, position Position) error {
+	if err := d.insert(cards, position); err != nil {
+		return err
+	}
+
+	d.reordered()
+	d.record(Event{Type: EventInserted, Cards: cards, Position: position, Order: d.Cards})
+	return nil
+}
+
+// insert inserts the cards as Insert does, without recording an event.
+func (d *Deck) insert(cards []card.Card, position Position) error {
+	if len(cards) == 0 {
+		return errors.New("at least one card must be inserted")
+	}
+
+	if position < PositionRandom || int(position) > d.Remaining {
+		return fmt.Errorf("%w: can not insert at position %d of %d", ErrInvalidPosition, position, d.Remaining)
+	}
+
+	drawn := cloneCards(d.Drawn)
+	piles := make(map[string][]card.Card, len(d.Piles))
+	for name, pile := range d.Piles {
+		piles[name] = cloneCards(pile.Cards)
+	}
+
+	var added []card.Card
+	for _, c := range cards {
+		if index := indexOf(drawn, c); index != -1 {
+			drawn = append(drawn[:index], drawn[index+1:]...)
+			continue
+		}
+
+		if name, found := pileHolding(piles, c); found {
+			index := indexOf(piles[name], c)
+			piles[name] = append(piles[name][:index], piles[name][index+1:]...)
+			continue
+		}
+
+		added = append(added, c)
+	}
+
+	if err := d.checkCopies(added); err != nil {
+		return err
+	}
+
+	d.Drawn = drawn
+	for name, pileCards := range piles {
+		d.Piles[name].Cards = pileCards
+	}
+	d.insertAt(cards, position)
+	return nil
+}
+
+// checkCopies checks that adding the cards to the Deck would not make it hold more copies of a card than DeckCount.
+func (d *Deck) checkCopies(added []card.Card) error {
+	if len(added) == 0 {
+		return nil
+	}
+
+	maxCopies := d.DeckCount
+	if maxCopies < 1 {
+		maxCopies = 1
+	}
+
+	cardCount := make(map[card.Card]int)
+	count := func(cards []card.Card) {
+		for _, c := range cards {
+			cardCount[c]++
+		}
+	}
+	count(d.Cards)
+	count(d.Drawn)
+	for _, pile := range d.Piles {
+		count(pile.Cards)
+	}
+
+	for _, c := range added {
+		cardCount[c]++
+		if cardCount[c] > maxCopies {
+			return fmt.Errorf("%w: card %s is already in the deck %d times", ErrDuplicateCard, c, maxCopies)
+		}
+	}
+	return nil
+}
+
+// insertAt puts the cards in the Deck at the given position, which must be valid.
+func (d *Deck) insertAt(cards []card.Card, position Position) {
+	switch position {
+	case PositionBottom:
+		d.putBack(cards)
+	case PositionRandom:
+		shuffler := d.Shuffler()
+		for _, c := range cards {
+			d.insertAt([]card.Card{c}, Position(shuffler.Intn(d.Remaining+1)))
+		}
+	default:
+		index := int(position)
+		newCards := make([]card.Card, 0, len(d.Cards)+len(cards))
+		newCards = append(newCards, d.Cards[:index]...)
+		newCards = append(newCards, cards...)
+		newCards = append(newCards, d.Cards[index:]...)
+		d.Cards = newCards
+		d.Remaining += len(cards)
+	}
+}
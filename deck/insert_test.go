@@ -0,0 +1,176 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestInsert(t *testing.T) {
+	aceOfSpades := card.Card{Rank: card.Ace, Suit: card.Spades}
+	kingOfDiamonds := card.Card{Rank: card.King, Suit: card.Diamonds}
+	aceOfClubs := card.Card{Rank: card.Ace, Suit: card.Clubs}
+	twoOfClubs := card.Card{Rank: card.Two, Suit: card.Clubs}
+	blackJoker := card.Card{Rank: card.Joker, Suit: card.Black}
+
+	testCases := []struct {
+		name          string
+		cards         []card.Card
+		position      Position
+		expectedCards []card.Card
+	}{
+		{
+			name:          "on top",
+			cards:         []card.Card{blackJoker, twoOfClubs},
+			position:      PositionTop,
+			expectedCards: []card.Card{blackJoker, twoOfClubs, aceOfSpades, kingOfDiamonds, aceOfClubs},
+		},
+		{
+			name:          "at the bottom",
+			cards:         []card.Card{blackJoker},
+			position:      PositionBottom,
+			expectedCards: []card.Card{aceOfSpades, kingOfDiamonds, aceOfClubs, blackJoker},
+		},
+		{
+			name:          "at an index",
+			cards:         []card.Card{blackJoker, twoOfClubs},
+			position:      1,
+			expectedCards: []card.Card{aceOfSpades, blackJoker, twoOfClubs, kingOfDiamonds, aceOfClubs},
+		},
+		{
+			name:          "after the last card",
+			cards:         []card.Card{blackJoker},
+			position:      3,
+			expectedCards: []card.Card{aceOfSpades, kingOfDiamonds, aceOfClubs, blackJoker},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deck, _ := NewPartialDeck([]string{"AS", "KD", "AC"})
+
+			err := deck.Insert(tc.cards, tc.position)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCards, deck.Cards)
+			assert.Equal(t, len(tc.expectedCards), deck.Remaining)
+		})
+	}
+}
+
+func TestInsertRandom(t *testing.T) {
+	deck := NewStandardDeck()
+	_, err := deck.DrawCards([]string{"AS"})
+	require.NoError(t, err)
+
+	// Without a seed, the joker should end up anywhere in the deck.
+	err = deck.Insert([]card.Card{{Rank: card.Joker, Suit: card.Red}}, PositionRandom)
+	require.NoError(t, err)
+	assert.Equal(t, 52, deck.Remaining)
+	assert.Contains(t, deck.Cards, card.Card{Rank: card.Joker, Suit: card.Red})
+
+	// Seeded decks insert the cards at the same positions.
+	deck1, _ := NewPartialDeck([]string{"AS", "KD", "AC", "2C", "KH"})
+	deck2, _ := NewPartialDeck([]string{"AS", "KD", "AC", "2C", "KH"})
+	deck1.SetShuffler(NewSeededShuffler(42))
+	deck2.SetShuffler(NewSeededShuffler(42))
+	jokers := []card.Card{{Rank: card.Joker, Suit: card.Black}, {Rank: card.Joker, Suit: card.Red}}
+	require.NoError(t, deck1.Insert(jokers, PositionRandom))
+	require.NoError(t, deck2.Insert(jokers, PositionRandom))
+	assert.Equal(t, deck1.Cards, deck2.Cards)
+	assert.Len(t, deck1.Cards, 7)
+}
+
+func TestInsertPutsBackDrawnCards(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD", "AC", "2C"})
+	_, err := deck.Draw(1)
+	require.NoError(t, err)
+	_, err = deck.DrawToPile("alice", 1)
+	require.NoError(t, err)
+
+	// Drawn cards, and cards in piles, are put back in the middle of the deck.
+	err = deck.Insert([]card.Card{{Rank: card.Ace, Suit: card.Spades}, {Rank: card.King, Suit: card.Diamonds}}, 1)
+	require.NoError(t, err)
+	expectedCards, _ := card.FromStrings([]string{"AC", "AS", "KD", "2C"})
+	assert.Equal(t, expectedCards, deck.Cards)
+	assert.Empty(t, deck.Drawn)
+	assert.Empty(t, deck.Piles["alice"].Cards)
+	assert.Equal(t, 4, deck.Size())
+}
+
+func TestInsertInvalid(t *testing.T) {
+	aceOfSpades := card.Card{Rank: card.Ace, Suit: card.Spades}
+
+	testCases := []struct {
+		name        string
+		newDeck     func() Deck
+		cards       []card.Card
+		position    Position
+		expectedErr error
+	}{
+		{
+			name: "card already in the deck",
+			newDeck: func() Deck {
+				return NewStandardDeck()
+			},
+			cards:       []card.Card{aceOfSpades},
+			position:    PositionTop,
+			expectedErr: ErrDuplicateCard,
+		},
+		{
+			name: "card repeated",
+			newDeck: func() Deck {
+				deck, _ := NewPartialDeck([]string{"KD"})
+				return deck
+			},
+			cards:       []card.Card{aceOfSpades, aceOfSpades},
+			position:    PositionTop,
+			expectedErr: ErrDuplicateCard,
+		},
+		{
+			name: "more copies than the decks of a shoe",
+			newDeck: func() Deck {
+				deck, _ := NewPartialShoe([]string{"AS", "AS"}, 2)
+				return deck
+			},
+			cards:       []card.Card{aceOfSpades},
+			position:    PositionBottom,
+			expectedErr: ErrDuplicateCard,
+		},
+		{
+			name: "position after the bottom",
+			newDeck: func() Deck {
+				deck, _ := NewPartialDeck([]string{"KD"})
+				return deck
+			},
+			cards:       []card.Card{aceOfSpades},
+			position:    2,
+			expectedErr: ErrInvalidPosition,
+		},
+		{
+			name: "negative position",
+			newDeck: func() Deck {
+				deck, _ := NewPartialDeck([]string{"KD"})
+				return deck
+			},
+			cards:       []card.Card{aceOfSpades},
+			position:    -3,
+			expectedErr: ErrInvalidPosition,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deck := tc.newDeck()
+			originalCards := cloneCards(deck.Cards)
+
+			err := deck.Insert(tc.cards, tc.position)
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, originalCards, deck.Cards, "Nothing is inserted when a card can not be inserted")
+		})
+	}
+
+	// A shoe may hold a card as many times as it has decks.
+	shoe, _ := NewPartialShoe([]string{"AS"}, 2)
+	assert.NoError(t, shoe.Insert([]card.Card{aceOfSpades}, PositionTop))
+}
@@ -0,0 +1,52 @@
+package deck
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"deck-of-cards/card"
+	"encoding/hex"
+)
+
+// IssueOwnerToken generates a new secret owner token for the Deck (e.g. for the dealer of a game), and returns it.
+// Only a hash of the token is kept by the Deck, so the token can not be recovered from a stored Deck.
+// Issuing a new token invalidates the previous one.
+func (d *Deck) IssueOwnerToken() (string, error) {
+	token, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+
+	d.OwnerTokenHash = hashToken(token)
+	return token, nil
+}
+
+// SetOwnerToken makes token, issued for another Deck, the owner token of the Deck too, so a single owner token can own
+// several Decks (e.g. every deck of a table). It invalidates the previous owner token of the Deck.
+func (d *Deck) SetOwnerToken(token string) {
+	d.OwnerTokenHash = hashToken(token)
+}
+
+// IsOwner checks whether the given token is the owner token of the Deck. It is always false for Decks without an
+// owner token.
+func (d *Deck) IsOwner(token string) bool {
+	if d.OwnerTokenHash == "" || token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(d.OwnerTokenHash)) == 1
+}
+
+// UnorderedCards returns a copy of the cards remaining in the Deck, sorted in the order of a new deck. It tells which
+// cards are left to be drawn, without telling in which order they will be drawn.
+func (d *Deck) UnorderedCards() []card.Card {
+	cards := make([]card.Card, len(d.Cards))
+	copy(cards, d.Cards)
+	card.Sort(cards)
+	return cards
+}
+
+// hashToken returns the hex encoded SHA-256 of the token.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
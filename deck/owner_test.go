@@ -0,0 +1,36 @@
+package deck
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestOwnerToken(t *testing.T) {
+	deck := NewStandardDeck()
+	assert.False(t, deck.IsOwner(""), "A deck without owner token has no owner")
+	assert.False(t, deck.IsOwner("anything"), "A deck without owner token has no owner")
+
+	token, err := deck.IssueOwnerToken()
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.NotContains(t, deck.OwnerTokenHash, token, "The token itself is not kept by the deck")
+
+	assert.True(t, deck.IsOwner(token))
+	assert.False(t, deck.IsOwner(""))
+	assert.False(t, deck.IsOwner(token+"x"))
+
+	newToken, err := deck.IssueOwnerToken()
+	require.NoError(t, err)
+	assert.True(t, deck.IsOwner(newToken))
+	assert.False(t, deck.IsOwner(token), "Issuing a new token invalidates the previous one")
+}
+
+func TestUnorderedCards(t *testing.T) {
+	deck := NewStandardDeck(WithJokers())
+	deck.Shuffle()
+	shuffledCards := append(deck.Cards[:0:0], deck.Cards...)
+
+	assert.Equal(t, NewStandardDeck(WithJokers()).Cards, deck.UnorderedCards())
+	assert.Equal(t, shuffledCards, deck.Cards, "The deck itself is not sorted")
+}
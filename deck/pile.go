@@ -0,0 +1,248 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// DiscardPile is the name of the pile which refills a Deck with AutoReshuffle when it runs out of cards.
+const DiscardPile = "discard"
+
+// Pile represents a named pile of cards attached to a Deck, such as a player's hand or a discard pile.
+// Every card in a pile was drawn from the Deck that owns it.
+type Pile struct {
+	// Name identifies the pile inside its Deck.
+	Name string
+	// Cards holds the cards in the pile. The first one in the array is the top of the pile.
+	Cards []card.Card
+}
+
+// Remaining returns the number of cards in the Pile.
+func (p *Pile) Remaining() int {
+	return len(p.Cards)
+}
+
+// add places the cards on top of the Pile, one at a time. The last of the given cards ends up at the top,
+// so adding cards in one go or one by one results in the same Pile.
+func (p *Pile) add(cards []card.Card) {
+	pileCards := make([]card.Card, 0, len(cards)+len(p.Cards))
+	for i := len(cards) - 1; i >= 0; i-- {
+		pileCards = append(pileCards, cards[i])
+	}
+	p.Cards = append(pileCards, p.Cards...)
+}
+
+// draw removes and returns the specified number of cards from the top of the Pile.
+func (p *Pile) draw(count int) ([]card.Card, error) {
+	if count > len(p.Cards) {
+		return nil, fmt.Errorf("%w in the pile", ErrNotEnoughCards)
+	}
+
+	if count <= 0 {
+		return nil, fmt.Errorf("draw count should be positive")
+	}
+
+	drawnCards := make([]card.Card, count)
+	copy(drawnCards, p.Cards[:count])
+	p.Cards = p.Cards[count:]
+
+	return drawnCards, nil
+}
+
+// Pile returns the pile with the given name. It returns ErrPileNotFound if the Deck has no such pile.
+func (d *Deck) Pile(name string) (*Pile, error) {
+	pile, exists := d.Piles[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: '%s'", ErrPileNotFound, name)
+	}
+	return pile, nil
+}
+
+// pileOrCreate returns the pile with the given name, creating an empty one if it does not exist yet.
+func (d *Deck) pileOrCreate(name string) (*Pile, error) {
+	if name == "" {
+		return nil, errors.New("pile name can not be empty")
+	}
+
+	if d.Piles == nil {
+		d.Piles = make(map[string]*Pile)
+	}
+
+	pile, exists := d.Piles[name]
+	if !exists {
+		pile = &Pile{Name: name}
+		d.Piles[name] = pile
+	}
+	return pile, nil
+}
+
+// AddToPile moves the given cards from the Deck's drawn cards to the top of the named pile.
+// The pile is created if it does not exist yet.
+// It returns ErrCardNotDrawn if any of the cards has not been drawn from the Deck. In that case, nothing is moved.
+func (d *Deck) AddToPile(name string, cards []card.Card) error {
+	if err := d.addToPile(name, cards); err != nil {
+		return err
+	}
+
+	d.record(Event{Type: EventAddedToPile, Cards: cards, Pile: name})
+	return nil
+}
+
+// addToPile moves the cards as AddToPile does, without recording an event.
+func (d *Deck) addToPile(name string, cards []card.Card) error {
+	if len(cards) == 0 {
+		return errors.New("at least one card must be added to the pile")
+	}
+
+	drawn, err := removeCards(d.Drawn, cards, ErrCardNotDrawn)
+	if err != nil {
+		return err
+	}
+
+	pile, err := d.pileOrCreate(name)
+	if err != nil {
+		return err
+	}
+
+	d.Drawn = drawn
+	pile.add(cards)
+	return nil
+}
+
+// DrawToPile draws the specified number of cards from the top of the Deck, and places them directly on the
+// named pile. The pile is created if it does not exist yet, once the cards are drawn: a failed draw does not leave an
+// empty pile behind.
+func (d *Deck) DrawToPile(name string, count int) ([]card.Card, error) {
+	if name == "" {
+		return nil, errors.New("pile name can not be empty")
+	}
+
+	drawnCards, err := d.draw(count)
+	if err != nil {
+		return nil, err
+	}
+	pile, err := d.pileOrCreate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// draw keeps track of the drawn cards, but these are now in the pile.
+	d.Drawn = d.Drawn[:len(d.Drawn)-len(drawnCards)]
+	pile.add(drawnCards)
+	d.record(Event{Type: EventDrawn, Cards: drawnCards, Pile: name})
+
+	return drawnCards, nil
+}
+
+// DrawFromPile removes and returns the specified number of cards from the top of the named pile.
+// The cards are then held as drawn cards of the Deck.
+func (d *Deck) DrawFromPile(name string, count int) ([]card.Card, error) {
+	pile, err := d.Pile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	drawnCards, err := pile.draw(count)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Drawn = append(d.Drawn, drawnCards...)
+	d.record(Event{Type: EventDrawnFromPile, Cards: drawnCards, Pile: name})
+	return drawnCards, nil
+}
+
+// ShufflePile shuffles the cards in the named pile, using the Deck's Shuffler.
+func (d *Deck) ShufflePile(name string) error {
+	pile, err := d.Pile(name)
+	if err != nil {
+		return err
+	}
+
+	d.Shuffler().Shuffle(len(pile.Cards), func(i, j int) {
+		pile.Cards[i], pile.Cards[j] = pile.Cards[j], pile.Cards[i]
+	})
+	d.record(Event{Type: EventPileShuffled, Pile: name, Order: pile.Cards})
+	return nil
+}
+
+// pileNames returns the names of the Deck's piles, sorted, so piles can be visited in a deterministic order.
+func (d *Deck) pileNames() []string {
+	names := make([]string, 0, len(d.Piles))
+	for name := range d.Piles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pileHolding returns the name of the first pile (by name) holding the card c.
+func pileHolding(piles map[string][]card.Card, c card.Card) (string, bool) {
+	names := make([]string, 0, len(piles))
+	for name := range piles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if indexOf(piles[name], c) != -1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// removeCards returns a copy of from without one instance of each of the given cards.
+// It returns notFound, naming the card, if any of the cards is not in from.
+func removeCards(from []card.Card, cards []card.Card, notFound error) ([]card.Card, error) {
+	remaining := make([]card.Card, len(from))
+	copy(remaining, from)
+
+	for _, c := range cards {
+		index := indexOf(remaining, c)
+		if index == -1 {
+			return nil, fmt.Errorf("%w: %s", notFound, c)
+		}
+		remaining = append(remaining[:index], remaining[index+1:]...)
+	}
+
+	return remaining, nil
+}
+
+// removeCardsAt returns a copy of from without the given cards, taken at the given positions, each counted once the
+// previous cards are removed (see Event.Positions). Without positions, the first instance of each card is taken, as
+// removeCards does: for cards drawn from the top, it is the one on top.
+// It returns ErrCardNotInDeck, naming the card, if any of the cards is not at its position.
+func removeCardsAt(from []card.Card, cards []card.Card, positions []int) ([]card.Card, error) {
+	if positions == nil {
+		return removeCards(from, cards, ErrCardNotInDeck)
+	}
+	if len(positions) != len(cards) {
+		return nil, errors.New("each card must have a position")
+	}
+
+	remaining := make([]card.Card, len(from))
+	copy(remaining, from)
+
+	for i, c := range cards {
+		position := positions[i]
+		if position < 0 || position >= len(remaining) || remaining[position] != c {
+			return nil, fmt.Errorf("%w: %s at position %d", ErrCardNotInDeck, c, position)
+		}
+		remaining = append(remaining[:position], remaining[position+1:]...)
+	}
+
+	return remaining, nil
+}
+
+// indexOf returns the index of the first instance of c in cards, or -1 if c is not in cards.
+func indexOf(cards []card.Card, c card.Card) int {
+	for i, other := range cards {
+		if other == c {
+			return i
+		}
+	}
+	return -1
+}
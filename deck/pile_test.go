@@ -0,0 +1,166 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDrawKeepsTrackOfDrawnCards(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD", "AC"})
+
+	drawnCards, err := deck.Draw(2)
+	require.NoError(t, err)
+
+	assert.Equal(t, drawnCards, deck.Drawn, "Drawn cards are held by the deck")
+}
+
+func TestAddToPile(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD", "AC", "2C"})
+	_, err := deck.Draw(3)
+	require.NoError(t, err)
+
+	err = deck.AddToPile("discard", []card.Card{{Rank: card.King, Suit: card.Diamonds}, {Rank: card.Ace, Suit: card.Spades}})
+	require.NoError(t, err)
+
+	pile, err := deck.Pile("discard")
+	require.NoError(t, err)
+	assert.Equal(t, 2, pile.Remaining())
+	assert.Equal(t, "AS", pile.Cards[0].String(), "The last added card is on top of the pile")
+	assert.Equal(t, []card.Card{{Rank: card.Ace, Suit: card.Clubs}}, deck.Drawn, "Cards in a pile are no longer drawn")
+}
+
+func TestAddToPileInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pileName string
+		cards    []string
+	}{
+		{
+			name:     "card not drawn yet",
+			pileName: "discard",
+			cards:    []string{"2C"},
+		},
+		{
+			name:     "card drawn once but added twice",
+			pileName: "discard",
+			cards:    []string{"AS", "AS"},
+		},
+		{
+			name:     "no cards",
+			pileName: "discard",
+			cards:    []string{},
+		},
+		{
+			name:     "empty pile name",
+			pileName: "",
+			cards:    []string{"AS"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deck, _ := NewPartialDeck([]string{"AS", "KD", "2C"})
+			_, err := deck.Draw(2)
+			require.NoError(t, err)
+
+			cards, err := card.FromStrings(tc.cards)
+			require.NoError(t, err)
+
+			err = deck.AddToPile(tc.pileName, cards)
+			require.Error(t, err)
+			assert.Len(t, deck.Drawn, 2, "A failed add does not move any card")
+		})
+	}
+}
+
+func TestDrawToPile(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD", "AC", "2C"})
+
+	_, err := deck.DrawToPile("alice", 2)
+	require.NoError(t, err)
+	_, err = deck.DrawToPile("alice", 1)
+	require.NoError(t, err)
+
+	pile, err := deck.Pile("alice")
+	require.NoError(t, err)
+	assert.Equal(t, "AC", pile.Cards[0].String(), "Last drawn card is on top of the pile")
+	assert.Equal(t, 3, pile.Remaining())
+	assert.Equal(t, 1, deck.Remaining)
+	assert.Empty(t, deck.Drawn, "Cards drawn to a pile are not held as drawn cards")
+
+	_, err = deck.DrawToPile("alice", 5)
+	assert.Error(t, err, "Drawing more cards than remaining should return an error")
+	assert.Equal(t, 3, pile.Remaining(), "A failed draw does not change the pile")
+
+	_, err = deck.DrawToPile("bob", 5)
+	assert.Error(t, err)
+	_, err = deck.Pile("bob")
+	assert.ErrorIs(t, err, ErrPileNotFound, "A failed draw does not create the pile")
+}
+
+func TestDrawFromPile(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD", "AC", "2C"})
+	_, err := deck.DrawToPile("discard", 3)
+	require.NoError(t, err)
+
+	drawnCards, err := deck.DrawFromPile("discard", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []card.Card{{Rank: card.Ace, Suit: card.Clubs}, {Rank: card.King, Suit: card.Diamonds}}, drawnCards, "Last drawn card is on top of the pile")
+	assert.Equal(t, drawnCards, deck.Drawn)
+
+	_, err = deck.DrawFromPile("discard", 2)
+	assert.Error(t, err, "Drawing more cards than in the pile should return an error")
+
+	_, err = deck.DrawFromPile("unknown", 1)
+	assert.Error(t, err, "Drawing from an unknown pile should return an error")
+}
+
+func TestShufflePile(t *testing.T) {
+	deck := NewStandardDeck()
+	_, err := deck.DrawToPile("discard", 52)
+	require.NoError(t, err)
+
+	pile, _ := deck.Pile("discard")
+	originalCards := make([]card.Card, len(pile.Cards))
+	copy(originalCards, pile.Cards)
+
+	err = deck.ShufflePile("discard")
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, originalCards, pile.Cards, "Shuffling keeps the same cards in the pile")
+	// As in TestShuffle, there is a *very* small probability of the order not changing.
+	assert.NotEqual(t, originalCards, pile.Cards, "the order of cards should change after shuffling")
+
+	assert.Error(t, deck.ShufflePile("unknown"), "Shuffling an unknown pile should return an error")
+}
+
+func TestDrawAutoReshuffle(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD", "AC", "2C"})
+	_, err := deck.DrawToPile(DiscardPile, 3)
+	require.NoError(t, err)
+
+	// Without AutoReshuffle, the discard pile stays where it is.
+	assert.Equal(t, 1, deck.Drawable())
+	_, err = deck.Draw(2)
+	require.ErrorIs(t, err, ErrNotEnoughCards)
+
+	deck.AutoReshuffle = true
+	assert.Equal(t, 4, deck.Drawable())
+
+	// The remaining card is drawn first, then the deck is refilled with the discard pile.
+	drawnCards, err := deck.Draw(2)
+	require.NoError(t, err)
+	assert.Equal(t, "2C", drawnCards[0].String())
+	assert.Equal(t, 2, deck.Remaining)
+	assert.Len(t, deck.Cards, 2)
+	assert.Equal(t, 4, deck.Size(), "Refilling the deck keeps every card")
+
+	pile, err := deck.Pile(DiscardPile)
+	require.NoError(t, err)
+	assert.Zero(t, pile.Remaining(), "The discard pile is emptied into the deck")
+
+	_, err = deck.Draw(3)
+	assert.ErrorIs(t, err, ErrNotEnoughCards, "The deck can not be refilled with an empty discard pile")
+}
@@ -0,0 +1,181 @@
+package deck
+
+import (
+	"crypto/hmac"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"deck-of-cards/card"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Proof lets anyone verify that the order of a Deck was fixed when the Deck was created (commit-reveal):
+//  1. On creation, the Deck is shuffled from a secret server seed and a seed chosen by the client, and only the
+//     Commitment (a hash of the shuffled order) is published.
+//  2. Once the Deck is exhausted or closed, the Proof is revealed. Anyone can then check that the Commitment matches
+//     the revealed Order, and that shuffling InitialCards with the revealed seeds results in that Order (see Verify).
+type Proof struct {
+	// Commitment is the hex encoded SHA-256 of Salt, followed by ":" and the card codes of Order, separated by ",".
+	Commitment string
+	// Salt is a random value hashed together with the order, so the order can not be brute-forced from the Commitment.
+	Salt string
+	// ServerSeed is the secret seed generated by the server. It is only known by the server until the Proof is revealed.
+	ServerSeed string
+	// ClientSeed is the seed provided by the client on creation. It may be empty.
+	ClientSeed string
+	// InitialCards holds the cards of the Deck before shuffling.
+	InitialCards []card.Card
+	// Order holds the cards of the Deck right after shuffling, in draw-order.
+	Order []card.Card
+}
+
+// CommitShuffle shuffles the Deck from a new secret server seed and the given client seed, and records a Proof of the
+// resulting order. The Commitment of the Proof can be published right away, while the rest of the Proof must stay
+// secret until RevealProof allows it.
+func (d *Deck) CommitShuffle(clientSeed string) error {
+	serverSeed, err := randomHex(32)
+	if err != nil {
+		return err
+	}
+	salt, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+
+	initialCards := make([]card.Card, len(d.Cards))
+	copy(initialCards, d.Cards)
+
+	d.shuffleWith(newFairShuffler(serverSeed, clientSeed))
+	d.record(Event{Type: EventShuffled, Method: "uniform", Committed: true, Order: d.Cards})
+
+	order := make([]card.Card, len(d.Cards))
+	copy(order, d.Cards)
+
+	d.Proof = &Proof{
+		Commitment:   commitment(salt, order),
+		Salt:         salt,
+		ServerSeed:   serverSeed,
+		ClientSeed:   clientSeed,
+		InitialCards: initialCards,
+		Order:        order,
+	}
+	return nil
+}
+
+// reordered discards the Proof of the Deck, as its cards were reordered (shuffled, cut, or with cards inserted): the
+// committed Order is not the order the cards are drawn in anymore, so revealing it would prove nothing.
+func (d *Deck) reordered() {
+	d.Proof = nil
+}
+
+// Close marks the Deck as closed: no more cards can be drawn from it, and its Proof can be revealed.
+func (d *Deck) Close() {
+	d.Closed = true
+	d.record(Event{Type: EventClosed})
+}
+
+// RevealProof returns the Proof of the Deck's order. It returns ErrProofUnavailable if the Deck was not created with a
+// commitment or was reordered since (see reordered), or if the Deck still has cards to be drawn and is not closed
+// (revealing the Proof would reveal them).
+func (d *Deck) RevealProof() (Proof, error) {
+	if d.Proof == nil {
+		return Proof{}, fmt.Errorf("%w: deck was not shuffled with a commitment, or was reordered since", ErrProofUnavailable)
+	}
+
+	if d.Remaining > 0 && !d.Closed {
+		return Proof{}, fmt.Errorf("%w until the deck is exhausted or closed", ErrProofUnavailable)
+	}
+
+	return *d.Proof, nil
+}
+
+// Verify checks that the Proof is consistent: the Commitment matches the Order, and the Order is the result of
+// shuffling InitialCards with the seeds of the Proof. It returns an error describing the first inconsistency.
+func (p Proof) Verify() error {
+	if commitment(p.Salt, p.Order) != p.Commitment {
+		return errors.New("commitment does not match the order")
+	}
+
+	cards := make([]card.Card, len(p.InitialCards))
+	copy(cards, p.InitialCards)
+	newFairShuffler(p.ServerSeed, p.ClientSeed).Shuffle(len(cards), func(i, j int) {
+		cards[i], cards[j] = cards[j], cards[i]
+	})
+
+	if len(cards) != len(p.Order) {
+		return errors.New("order does not have the same cards as the initial cards")
+	}
+	for i := range cards {
+		if cards[i] != p.Order[i] {
+			return fmt.Errorf("order does not match the seeds at position %d", i)
+		}
+	}
+
+	return nil
+}
+
+// commitment returns the hex encoded SHA-256 of the salt, followed by ":" and the codes of the cards, separated by ",".
+func commitment(salt string, cards []card.Card) string {
+	codes := make([]string, len(cards))
+	for i, c := range cards {
+		codes[i] = c.String()
+	}
+
+	hash := sha256.Sum256([]byte(salt + ":" + strings.Join(codes, ",")))
+	return hex.EncodeToString(hash[:])
+}
+
+// randomHex returns n random bytes from crypto/rand, hex encoded.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// fairShuffler is a deterministic Shuffler derived from a server seed and a client seed. Its random numbers are
+// HMAC-SHA256(server seed, client seed + ":" + counter), so they can not be predicted without the server seed, but
+// anyone can reproduce them once it is revealed.
+type fairShuffler struct {
+	serverSeed string
+	clientSeed string
+	counter    uint64
+}
+
+func newFairShuffler(serverSeed, clientSeed string) *fairShuffler {
+	return &fairShuffler{serverSeed: serverSeed, clientSeed: clientSeed}
+}
+
+// next returns the next 64 random bits of the shuffler.
+func (s *fairShuffler) next() uint64 {
+	mac := hmac.New(sha256.New, []byte(s.serverSeed))
+	mac.Write([]byte(fmt.Sprintf("%s:%d", s.clientSeed, s.counter)))
+	s.counter++
+	return binary.BigEndian.Uint64(mac.Sum(nil)[:8])
+}
+
+// Intn returns a uniformly distributed random integer in [0, n). Values that would bias the result towards lower
+// numbers are rejected, and a new one is drawn.
+func (s *fairShuffler) Intn(n int) int {
+	if n <= 0 {
+		panic("invalid argument to Intn")
+	}
+
+	bound := uint64(n)
+	limit := ^uint64(0) - (^uint64(0) % bound)
+	for {
+		x := s.next()
+		if x < limit {
+			return int(x % bound)
+		}
+	}
+}
+
+// Shuffle randomizes the order of n elements with the Fisher–Yates algorithm, swapping them with swap.
+func (s *fairShuffler) Shuffle(n int, swap func(i, j int)) {
+	fisherYates(s, n, swap)
+}
@@ -0,0 +1,184 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCommitShuffle(t *testing.T) {
+	deck := NewStandardDeck()
+
+	err := deck.CommitShuffle("client seed")
+	require.NoError(t, err)
+
+	require.NotNil(t, deck.Proof)
+	assert.True(t, deck.Shuffled, "deck is marked shuffled")
+	assert.Len(t, deck.Proof.Commitment, 64, "Commitment is a hex encoded SHA-256")
+	assert.Equal(t, NewStandardDeck().Cards, deck.Proof.InitialCards)
+	assert.Equal(t, deck.Cards, deck.Proof.Order)
+	assert.NoError(t, deck.Proof.Verify())
+
+	other := NewStandardDeck()
+	err = other.CommitShuffle("client seed")
+	require.NoError(t, err)
+	// There is a *very* small probability of two server seeds yielding the same order.
+	assert.NotEqual(t, deck.Cards, other.Cards, "Each deck gets its own server seed")
+}
+
+func TestRevealProof(t *testing.T) {
+	t.Run("deck without commitment", func(t *testing.T) {
+		deck := NewStandardDeck()
+		deck.Shuffle()
+		deck.Close()
+
+		_, err := deck.RevealProof()
+		assert.Error(t, err)
+	})
+
+	t.Run("deck with cards remaining", func(t *testing.T) {
+		deck := NewStandardDeck()
+		require.NoError(t, deck.CommitShuffle(""))
+		_, err := deck.Draw(51)
+		require.NoError(t, err)
+
+		_, err = deck.RevealProof()
+		assert.Error(t, err, "Revealing the proof would reveal the remaining cards")
+	})
+
+	t.Run("exhausted deck", func(t *testing.T) {
+		deck := NewStandardDeck()
+		require.NoError(t, deck.CommitShuffle(""))
+		drawnCards, err := deck.Draw(52)
+		require.NoError(t, err)
+
+		proof, err := deck.RevealProof()
+		require.NoError(t, err)
+		assert.Equal(t, drawnCards, proof.Order, "The cards were drawn in the committed order")
+		assert.NoError(t, proof.Verify())
+	})
+
+	t.Run("closed deck", func(t *testing.T) {
+		deck := NewStandardDeck()
+		require.NoError(t, deck.CommitShuffle("abc"))
+		deck.Close()
+
+		_, err := deck.Draw(1)
+		assert.Error(t, err, "No cards can be drawn from a closed deck")
+
+		proof, err := deck.RevealProof()
+		require.NoError(t, err)
+		assert.Equal(t, "abc", proof.ClientSeed)
+		assert.NoError(t, proof.Verify())
+	})
+}
+
+func TestReorderingDiscardsProof(t *testing.T) {
+	testCases := []struct {
+		name    string
+		reorder func(d *Deck) error
+	}{
+		{
+			name:    "shuffle",
+			reorder: func(d *Deck) error { d.Shuffle(); return nil },
+		},
+		{
+			name:    "riffle shuffle",
+			reorder: func(d *Deck) error { d.RiffleShuffle(); return nil },
+		},
+		{
+			name:    "cut",
+			reorder: func(d *Deck) error { return d.Cut(10) },
+		},
+		{
+			name: "insert",
+			reorder: func(d *Deck) error {
+				drawnCards, err := d.Draw(1)
+				if err != nil {
+					return err
+				}
+				return d.Insert(drawnCards, PositionRandom)
+			},
+		},
+		{
+			name: "reshuffle of the discard pile",
+			reorder: func(d *Deck) error {
+				d.AutoReshuffle = true
+				if _, err := d.DrawToPile(DiscardPile, 50); err != nil {
+					return err
+				}
+				_, err := d.Draw(3)
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deck := NewStandardDeck()
+			require.NoError(t, deck.CommitShuffle(""))
+			require.NoError(t, tc.reorder(&deck))
+			deck.Close()
+
+			_, err := deck.RevealProof()
+			assert.ErrorIs(t, err, ErrProofUnavailable, "The committed order is not the order of the deck anymore")
+		})
+	}
+}
+
+func TestProofVerifyTampered(t *testing.T) {
+	testCases := []struct {
+		name   string
+		tamper func(p *Proof)
+	}{
+		{
+			name:   "swapped cards in the order",
+			tamper: func(p *Proof) { p.Order[0], p.Order[1] = p.Order[1], p.Order[0] },
+		},
+		{
+			name: "order consistent with commitment, but not with the seeds",
+			tamper: func(p *Proof) {
+				p.Order[0], p.Order[1] = p.Order[1], p.Order[0]
+				p.Commitment = commitment(p.Salt, p.Order)
+			},
+		},
+		{
+			name:   "different client seed",
+			tamper: func(p *Proof) { p.ClientSeed = "another seed" },
+		},
+		{
+			name:   "different salt",
+			tamper: func(p *Proof) { p.Salt = "00" },
+		},
+		{
+			name:   "missing card",
+			tamper: func(p *Proof) { p.InitialCards = p.InitialCards[1:] },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// A full deck, so another seed is all but certain to result in another order.
+			deck := NewStandardDeck()
+			require.NoError(t, deck.CommitShuffle("client seed"))
+
+			proof := *deck.Proof
+			proof.Order = append([]card.Card(nil), proof.Order...)
+			proof.InitialCards = append([]card.Card(nil), proof.InitialCards...)
+			tc.tamper(&proof)
+
+			assert.Error(t, proof.Verify())
+		})
+	}
+}
+
+func TestFairShufflerIsDeterministic(t *testing.T) {
+	deck1 := NewStandardDeck()
+	deck2 := NewStandardDeck()
+
+	deck1.ShuffleWith(newFairShuffler("server", "client"))
+	deck2.ShuffleWith(newFairShuffler("server", "client"))
+
+	assert.Equal(t, deck1.Cards, deck2.Cards)
+}
@@ -0,0 +1,184 @@
+package deck
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"github.com/google/uuid"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrDeckNotFound is returned by a DeckRepository when there is no deck with the given ID.
+var ErrDeckNotFound = errors.New("deck not found")
+
+// ErrDeckExpired is returned by a DeckRepository when the deck with the given ID expired (see StoreConfig).
+var ErrDeckExpired = errors.New("deck expired")
+
+// DeckRepository stores decks by their ID. Implementations must be safe for concurrent use.
+//
+// Store keeps the decks in memory, and BoltStore keeps them in a file on disk, so they outlive the server.
+type DeckRepository interface {
+	// Add adds a new deck. It returns an error if a deck with the same ID already exists.
+	Add(deck *Deck) error
+	// Get retrieves a deck by its ID. It returns ErrDeckNotFound if there is no such deck, or ErrDeckExpired if it
+	// expired.
+	// Changes to the returned deck are not saved: use Update instead.
+	Get(id uuid.UUID) (*Deck, error)
+	// Remove removes a deck by its ID. It returns ErrDeckNotFound if there is no such deck.
+	Remove(id uuid.UUID) error
+	// RemoveMatching removes every deck matching filter, and returns how many decks were removed.
+	RemoveMatching(filter DeckFilter) (int, error)
+	// Update calls fn with the deck with the given ID, and saves the changes fn made to the deck, incrementing its
+	// Version. The events recorded by fn (see Deck.History) get the new Version, and the oldest events are archived
+	// if the History grew too long (see EventCompacted). If fn returns an error, the error is returned and the changes
+	// are not saved.
+	// It returns ErrDeckNotFound if there is no such deck, or ErrDeckExpired if it expired.
+	Update(id uuid.UUID, fn func(*Deck) error) error
+	// ArchivedHistory returns the events moved out of the History of the deck with the given ID (see EventCompacted)
+	// whose Sequence is greater than after, in order. At most limit events are returned, unless limit is zero.
+	// It returns ErrDeckNotFound if there is no such deck, or ErrDeckExpired if it expired.
+	ArchivedHistory(id uuid.UUID, after int, limit int) ([]Event, error)
+	// List returns a page of the decks matching filter, from the oldest to the newest, and the cursor of the next page.
+	// The cursor is empty if there are no more decks. It returns ErrInvalidCursor if the cursor of page is not valid.
+	// Listing decks does not count as using them (e.g. for expiration).
+	List(filter DeckFilter, page Page) ([]*Deck, string, error)
+}
+
+// bumpVersion increments the Version of the Deck, from version, once it was updated by a DeckRepository, and gives
+// the new Version to the events recorded by the update: those after the first recorded events of the History.
+func (d *Deck) bumpVersion(version uint64, recorded int) {
+	d.Version = version + 1
+	for i := recorded; i < len(d.History); i++ {
+		d.History[i].Version = d.Version
+	}
+}
+
+// pageEvents returns the events, sorted by Sequence, whose Sequence is greater than after, at most limit of them unless
+// limit is zero. The returned slice is a copy, so it can be appended to.
+func pageEvents(events []Event, after int, limit int) []Event {
+	start := sort.Search(len(events), func(i int) bool {
+		return events[i].Sequence > after
+	})
+	events = events[start:]
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return append([]Event(nil), events...)
+}
+
+// ErrInvalidCursor is returned by DeckRepository.List when the cursor of the page was not returned by List.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// DeckFilter selects decks to be listed by a DeckRepository. The zero value selects every deck.
+type DeckFilter struct {
+	// Owner selects the decks of the given owner (see Deck.Owner), if it is not empty.
+	Owner string
+	// OwnerToken selects the decks owned by the given owner token (see Deck.IsOwner), if it is not empty.
+	OwnerToken string
+	// Shuffled selects the shuffled (or not shuffled) decks, if it is not nil.
+	Shuffled *bool
+	// MinRemaining and MaxRemaining select the decks with at least (or at most) the given number of remaining cards,
+	// if they are not nil.
+	MinRemaining *int
+	MaxRemaining *int
+	// CreatedAfter selects the decks created after the given time, if it is not zero.
+	CreatedAfter time.Time
+	// CreatedBefore selects the decks created before the given time, if it is not zero.
+	CreatedBefore time.Time
+}
+
+// matches tells whether the deck is selected by the filter.
+func (f DeckFilter) matches(d *Deck) bool {
+	switch {
+	case f.Owner != "" && d.Owner != f.Owner:
+		return false
+	case f.OwnerToken != "" && !d.IsOwner(f.OwnerToken):
+		return false
+	case f.Shuffled != nil && d.Shuffled != *f.Shuffled:
+		return false
+	case f.MinRemaining != nil && d.Remaining < *f.MinRemaining:
+		return false
+	case f.MaxRemaining != nil && d.Remaining > *f.MaxRemaining:
+		return false
+	case !f.CreatedAfter.IsZero() && !d.CreatedAt.After(f.CreatedAfter):
+		return false
+	case !f.CreatedBefore.IsZero() && !d.CreatedAt.Before(f.CreatedBefore):
+		return false
+	}
+	return true
+}
+
+// Page selects a page of the decks listed by a DeckRepository. The zero value selects every deck.
+type Page struct {
+	// Cursor is the cursor returned with the previous page. The first page has an empty cursor.
+	Cursor string
+	// Limit is the maximum number of decks in the page. Zero means no limit.
+	Limit int
+}
+
+// paginate returns the page of decks, which must be sorted with sortDecks, and the cursor of the next page.
+// The cursor holds the creation time and ID of the last deck of the page, so decks added or removed in the meantime
+// do not shift the next pages.
+func paginate(decks []*Deck, page Page) ([]*Deck, string, error) {
+	if page.Cursor != "" {
+		createdAt, id, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start := sort.Search(len(decks), func(i int) bool {
+			return deckAfter(decks[i], createdAt, id)
+		})
+		decks = decks[start:]
+	}
+
+	if page.Limit <= 0 || len(decks) <= page.Limit {
+		return decks, "", nil
+	}
+	decks = decks[:page.Limit]
+	last := decks[len(decks)-1]
+	return decks, encodeCursor(last.CreatedAt, last.ID), nil
+}
+
+// deckAfter tells whether d is sorted after the deck created at createdAt with the given ID (see sortDecks).
+func deckAfter(d *Deck, createdAt time.Time, id uuid.UUID) bool {
+	if !d.CreatedAt.Equal(createdAt) {
+		return d.CreatedAt.After(createdAt)
+	}
+	return bytes.Compare(d.ID[:], id[:]) > 0
+}
+
+// encodeCursor returns the cursor of the page following the deck created at createdAt with the given ID.
+func encodeCursor(createdAt time.Time, id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(createdAt.UnixNano(), 10) + "_" + id.String()))
+}
+
+// decodeCursor returns the creation time and ID held by a cursor returned by encodeCursor.
+func decodeCursor(cursor string) (time.Time, uuid.UUID, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, ErrInvalidCursor
+	}
+	nanos, idStr, found := strings.Cut(string(data), "_")
+	if !found {
+		return time.Time{}, uuid.UUID{}, ErrInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, ErrInvalidCursor
+	}
+	return time.Unix(0, unixNano), id, nil
+}
+
+// sortDecks sorts decks from the oldest to the newest. Decks created at the same time are sorted by ID.
+func sortDecks(decks []*Deck) {
+	sort.Slice(decks, func(i, j int) bool {
+		return deckAfter(decks[j], decks[i].CreatedAt, decks[i].ID)
+	})
+}
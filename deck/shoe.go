@@ -0,0 +1,92 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// MaxShoeDeckCount is the maximum number of standard decks a shoe can be made of.
+// Casino games usually play with 6 or 8 decks.
+const MaxShoeDeckCount = 8
+
+// NewShoe creates a new Deck made of n standard decks (n * 52 cards), as used by casino games such as blackjack or
+// baccarat. Unlike a regular Deck, a shoe contains repeated cards by design: each card appears n times.
+// Options may change the composition of the shoe (e.g. WithJokers).
+// It returns an error if n is not between 1 and MaxShoeDeckCount.
+func NewShoe(n int, opts ...Option) (Deck, error) {
+	if err := validateDeckCount(n); err != nil {
+		return Deck{}, err
+	}
+
+	cards := make([]card.Card, 0, n*52)
+	for i := 0; i < n; i++ {
+		cards = append(cards, standardCards()...)
+	}
+
+	d := Deck{
+		ID:        uuid.New(),
+		Shuffled:  false,
+		Remaining: len(cards),
+		Cards:     cards,
+		DeckCount: n,
+		CreatedAt: creationTime(),
+	}
+	for _, opt := range opts {
+		opt(&d)
+	}
+	d.record(Event{Type: EventCreated, Order: d.Cards})
+
+	return d, nil
+}
+
+// NewPartialShoe creates a new Deck containing a custom set of cards based on the provided card codes, allowing each
+// card to be repeated up to n times (as if the cards were picked from n standard decks).
+// It returns an error if any of these happens:
+// 1. n is not between 1 and MaxShoeDeckCount.
+// 2. The codes array is empty.
+// 3. There are any invalid codes in the codes array (ErrInvalidCardCode).
+// 4. A code is repeated more than n times in the codes array (ErrDuplicateCard).
+func NewPartialShoe(codes []string, n int) (Deck, error) {
+	if err := validateDeckCount(n); err != nil {
+		return Deck{}, err
+	}
+
+	if len(codes) == 0 {
+		return Deck{}, errors.New("a deck must have at least one card")
+	}
+
+	cards, err := card.FromStrings(codes)
+	if err != nil {
+		return Deck{}, err
+	}
+
+	cardCount := make(map[card.Card]int)
+	for _, c := range cards {
+		cardCount[c]++
+		if cardCount[c] > n {
+			return Deck{}, fmt.Errorf("%w: card code %s is repeated more than %d times", ErrDuplicateCard, c, n)
+		}
+	}
+
+	d := Deck{
+		ID:        uuid.New(),
+		Shuffled:  false,
+		Remaining: len(cards),
+		Cards:     cards,
+		DeckCount: n,
+		CreatedAt: creationTime(),
+	}
+	d.record(Event{Type: EventCreated, Order: d.Cards})
+
+	return d, nil
+}
+
+// validateDeckCount checks that a shoe can be made of n standard decks.
+func validateDeckCount(n int) error {
+	if n < 1 || n > MaxShoeDeckCount {
+		return fmt.Errorf("deck count must be between 1 and %d", MaxShoeDeckCount)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewShoe(t *testing.T) {
+	shoe, err := NewShoe(6)
+	require.NoError(t, err)
+
+	assert.NotNil(t, shoe.ID, "Shoe ID should not be nil")
+	assert.False(t, shoe.Shuffled, "A shoe is not shuffled by default")
+	assert.Equal(t, 6, shoe.DeckCount)
+	assert.Equal(t, 6*52, shoe.Remaining)
+	assert.Len(t, shoe.Cards, 6*52)
+
+	cardCount := make(map[card.Card]int)
+	for _, c := range shoe.Cards {
+		cardCount[c]++
+	}
+	for _, c := range standardCards() {
+		assert.Equal(t, 6, cardCount[c], "There should be exactly six of each card in a 6-deck shoe")
+	}
+
+	drawnCards, err := shoe.Draw(60)
+	require.NoError(t, err)
+	assert.Len(t, drawnCards, 60, "Draw works across the decks of a shoe")
+	assert.Equal(t, 6*52-60, shoe.Remaining)
+}
+
+func TestNewShoeInvalidDeckCount(t *testing.T) {
+	for _, n := range []int{-1, 0, MaxShoeDeckCount + 1} {
+		_, err := NewShoe(n)
+		assert.Error(t, err, "A shoe can not be made of %d decks", n)
+	}
+}
+
+func TestNewPartialShoe(t *testing.T) {
+	testCases := []struct {
+		name        string
+		codes       []string
+		deckCount   int
+		expectedErr bool
+	}{
+		{
+			name:      "repeated codes within the deck count",
+			codes:     []string{"AS", "AS", "KD", "AS"},
+			deckCount: 3,
+		},
+		{
+			name:      "no repeated codes",
+			codes:     []string{"AS", "KD"},
+			deckCount: 2,
+		},
+		{
+			name:        "code repeated more than the deck count",
+			codes:       []string{"AS", "AS", "AS"},
+			deckCount:   2,
+			expectedErr: true,
+		},
+		{
+			name:        "repeated code in a single deck",
+			codes:       []string{"AS", "AS"},
+			deckCount:   1,
+			expectedErr: true,
+		},
+		{
+			name:        "invalid card code",
+			codes:       []string{"AS", "ZZ"},
+			deckCount:   2,
+			expectedErr: true,
+		},
+		{
+			name:        "no cards",
+			codes:       []string{},
+			deckCount:   2,
+			expectedErr: true,
+		},
+		{
+			name:        "invalid deck count",
+			codes:       []string{"AS"},
+			deckCount:   0,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shoe, err := NewPartialShoe(tc.codes, tc.deckCount)
+
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.codes), shoe.Remaining)
+			assert.Equal(t, tc.deckCount, shoe.DeckCount)
+			for i, code := range tc.codes {
+				assert.Equal(t, code, shoe.Cards[i].String(), "Shoe card should match the specified code")
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package deck
+
+import (
+	cryptorand "crypto/rand"
+	"math/big"
+	"math/rand"
+)
+
+// Shuffler is the source of randomness used by a Deck to shuffle its cards.
+// *rand.Rand implements Shuffler, so any math/rand source can be used (see NewSeededShuffler).
+type Shuffler interface {
+	// Shuffle pseudo-randomizes the order of n elements, swapping them with swap.
+	Shuffle(n int, swap func(i, j int))
+	// Intn returns a uniformly distributed random integer in [0, n). It panics if n <= 0.
+	Intn(n int) int
+}
+
+// SecureShuffler is a Shuffler backed by crypto/rand. Its shuffles can not be predicted, even by someone who knows
+// every previous shuffle. It is the default Shuffler of a Deck.
+type SecureShuffler struct{}
+
+// Intn returns a uniformly distributed random integer in [0, n), read from crypto/rand.
+// crypto/rand.Int samples by rejection, so the result is not biased towards lower values (as `x % n` would be).
+func (SecureShuffler) Intn(n int) int {
+	if n <= 0 {
+		panic("invalid argument to Intn")
+	}
+
+	x, err := cryptorand.Int(cryptorand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		// crypto/rand only fails if the operating system can not provide randomness.
+		// Falling back to a predictable shuffle would be worse than not shuffling at all.
+		panic("crypto/rand failed: " + err.Error())
+	}
+	return int(x.Int64())
+}
+
+// Shuffle randomizes the order of n elements with the Fisher–Yates algorithm, swapping them with swap.
+// Every permutation is equally likely.
+func (s SecureShuffler) Shuffle(n int, swap func(i, j int)) {
+	fisherYates(s, n, swap)
+}
+
+// SeededShuffler is a deterministic Shuffler: Decks shuffled by SeededShufflers created with the same seed always end
+// up in the same order. It must not be used when players should not be able to predict the cards.
+type SeededShuffler struct {
+	*rand.Rand
+	source *splitMix64
+}
+
+// NewSeededShuffler returns a SeededShuffler created with the given seed.
+func NewSeededShuffler(seed int64) *SeededShuffler {
+	source := &splitMix64{}
+	source.Seed(seed)
+	return &SeededShuffler{Rand: rand.New(source), source: source}
+}
+
+// restoreSeededShuffler returns a SeededShuffler created with the given seed, whose source is in the given state.
+// It lets a stored Deck keep shuffling exactly as it would have without being stored.
+func restoreSeededShuffler(seed int64, state uint64) *SeededShuffler {
+	source := &splitMix64{seed: seed, state: state}
+	return &SeededShuffler{Rand: rand.New(source), source: source}
+}
+
+// clone returns a SeededShuffler in the same state as s, which can be used without changing s.
+func (s *SeededShuffler) clone() *SeededShuffler {
+	return restoreSeededShuffler(s.source.seed, s.source.state)
+}
+
+// splitMix64 is the source of a SeededShuffler: the SplitMix64 generator. Its whole state is a single number, so
+// it can be copied and stored, which is not the case for the sources of math/rand.
+type splitMix64 struct {
+	seed  int64
+	state uint64
+}
+
+func (s *splitMix64) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *splitMix64) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+func (s *splitMix64) Seed(seed int64) {
+	s.seed = seed
+	s.state = uint64(seed)
+}
+
+// fisherYates randomizes the order of n elements, swapping them with swap, using s as the source of randomness.
+func fisherYates(s Shuffler, n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		j := s.Intn(i + 1)
+		swap(i, j)
+	}
+}
@@ -0,0 +1,100 @@
+package deck
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSecureShufflerIntn(t *testing.T) {
+	const n = 6
+	const samples = 60000
+
+	counts := make([]int, n)
+	for i := 0; i < samples; i++ {
+		x := SecureShuffler{}.Intn(n)
+		if !assert.True(t, x >= 0 && x < n, "Intn(%d) returned %d", n, x) {
+			return
+		}
+		counts[x]++
+	}
+
+	// Each value is expected 10000 times. The standard deviation is ~91, so 500 is a very loose bound.
+	for value, count := range counts {
+		assert.InDelta(t, samples/n, count, 500, "value %d should be as likely as every other value", value)
+	}
+
+	assert.Panics(t, func() { SecureShuffler{}.Intn(0) }, "Intn(0) has no valid result")
+}
+
+func TestSecureShufflerIsUnbiased(t *testing.T) {
+	const samples = 60000
+
+	// Every one of the 6 permutations of 3 elements should be equally likely.
+	counts := make(map[string]int)
+	for i := 0; i < samples; i++ {
+		elements := []string{"A", "B", "C"}
+		SecureShuffler{}.Shuffle(len(elements), func(i, j int) {
+			elements[i], elements[j] = elements[j], elements[i]
+		})
+		counts[fmt.Sprint(elements)]++
+	}
+
+	assert.Len(t, counts, 6, "every permutation should be reachable")
+	for permutation, count := range counts {
+		assert.InDelta(t, samples/6, count, 500, "permutation %s should be as likely as every other permutation", permutation)
+	}
+}
+
+func TestDeckShuffler(t *testing.T) {
+	deck := NewStandardDeck()
+	assert.Equal(t, SecureShuffler{}, deck.Shuffler(), "Decks are securely shuffled by default")
+
+	deck1 := NewStandardDeck()
+	deck2 := NewStandardDeck()
+	deck1.SetShuffler(NewSeededShuffler(42))
+	deck2.SetShuffler(NewSeededShuffler(42))
+
+	// Seeded decks stay reproducible across shuffles, and shuffling twice does not restore the first order.
+	deck1.Shuffle()
+	deck2.Shuffle()
+	assert.Equal(t, deck1.Cards, deck2.Cards)
+	firstOrder := append(deck1.Cards[:0:0], deck1.Cards...)
+
+	deck1.ReturnAll()
+	deck2.ReturnAll()
+	deck1.Shuffle()
+	deck2.Shuffle()
+	assert.Equal(t, deck1.Cards, deck2.Cards)
+	assert.NotEqual(t, firstOrder, deck1.Cards)
+}
+
+func TestDeckSeed(t *testing.T) {
+	deck := NewStandardDeck()
+	_, seeded := deck.Seed()
+	assert.False(t, seeded)
+
+	deck.SetShuffler(NewSeededShuffler(42))
+	seed, seeded := deck.Seed()
+	assert.True(t, seeded)
+	assert.Equal(t, int64(42), seed)
+}
+
+func TestSeededShufflerClone(t *testing.T) {
+	shuffler := NewSeededShuffler(42)
+	for i := 0; i < 1000; i++ {
+		shuffler.Intn(52)
+	}
+
+	clone := shuffler.clone()
+	assert.Equal(t, shuffler.source.state, clone.source.state)
+	for i := 0; i < 100; i++ {
+		expected := clone.Intn(52)
+		assert.Equal(t, expected, shuffler.Intn(52), "A clone continues where the shuffler is")
+	}
+
+	restored := restoreSeededShuffler(42, shuffler.source.state)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, shuffler.Intn(52), restored.Intn(52), "A restored shuffler continues where the shuffler was")
+	}
+}
@@ -0,0 +1,154 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"errors"
+	"fmt"
+)
+
+// The shuffles in this file model the way people shuffle cards by hand, for game simulations and card tricks. Unlike
+// Shuffle, most of them do not make every order equally likely: a deck needs about 7 riffle shuffles, or thousands of
+// overhand shuffles, to be well mixed. They all apply to the remaining cards of the Deck, and use the Deck's Shuffler
+// as the source of randomness.
+
+// overhandPacketSize is the average number of cards in each packet of an overhand shuffle.
+const overhandPacketSize = 4
+
+// Cut moves the top n cards of the Deck to the bottom, keeping their order. n must be between 1 and the number of
+// remaining cards minus one; otherwise, ErrInvalidPosition is returned.
+func (d *Deck) Cut(n int) error {
+	if n <= 0 || n >= d.Remaining {
+		return fmt.Errorf("%w: can not cut at position %d of %d", ErrInvalidPosition, n, d.Remaining)
+	}
+
+	cards := make([]card.Card, 0, len(d.Cards))
+	cards = append(cards, d.Cards[n:]...)
+	d.Cards = append(cards, d.Cards[:n]...)
+	d.reordered()
+	d.record(Event{Type: EventCut, Order: d.Cards})
+	return nil
+}
+
+// RiffleShuffle shuffles the Deck once, as a riffle shuffle following the Gilbert–Shannon–Reeds model: the Deck is cut
+// in two packets, with the size of the top packet following a binomial distribution, and cards are then dropped one at
+// a time from either packet, with a probability proportional to the size of the packet.
+func (d *Deck) RiffleShuffle() {
+	shuffler := d.Shuffler()
+	n := len(d.Cards)
+
+	cut := 0
+	for i := 0; i < n; i++ {
+		cut += shuffler.Intn(2)
+	}
+
+	top, bottom := d.Cards[:cut], d.Cards[cut:]
+	cards := make([]card.Card, 0, n)
+	for len(top) > 0 || len(bottom) > 0 {
+		if shuffler.Intn(len(top)+len(bottom)) < len(top) {
+			cards = append(cards, top[0])
+			top = top[1:]
+		} else {
+			cards = append(cards, bottom[0])
+			bottom = bottom[1:]
+		}
+	}
+
+	d.Cards = cards
+	d.Shuffled = true
+	d.reordered()
+	d.record(Event{Type: EventShuffled, Method: "riffle", Order: d.Cards})
+}
+
+// OverhandShuffle shuffles the Deck once, as an overhand shuffle: small packets of cards are taken from the top of the
+// Deck and dropped, one at a time, on top of a new pile, which reverses the order of the packets but not the order of
+// the cards within a packet. Each gap between two cards starts a new packet with probability 1/overhandPacketSize.
+func (d *Deck) OverhandShuffle() {
+	shuffler := d.Shuffler()
+	n := len(d.Cards)
+
+	cards := make([]card.Card, n)
+	end := n
+	start := 0
+	for i := 1; i <= n; i++ {
+		if i < n && shuffler.Intn(overhandPacketSize) != 0 {
+			continue
+		}
+		// The packet d.Cards[start:i] is dropped on the new pile, above the previous packets.
+		packet := d.Cards[start:i]
+		copy(cards[end-len(packet):end], packet)
+		end -= len(packet)
+		start = i
+	}
+
+	d.Cards = cards
+	d.Shuffled = true
+	d.reordered()
+	d.record(Event{Type: EventShuffled, Method: "overhand", Order: d.Cards})
+}
+
+// PileShuffle deals the Deck into k piles, one card at a time, and stacks the piles back in order: the first pile on
+// top, the last one at the bottom. As each card is dealt on top of its pile, the order of the cards within a pile is
+// reversed. A pile shuffle does not use any randomness: it separates cards which were next to each other (e.g. after a
+// game of solitaire), but the order of the Deck is still known. k must be between 2 and the number of remaining cards.
+func (d *Deck) PileShuffle(k int) error {
+	if k < 2 || k > d.Remaining {
+		return fmt.Errorf("number of piles must be between 2 and %d", d.Remaining)
+	}
+
+	piles := make([][]card.Card, k)
+	for i, c := range d.Cards {
+		piles[i%k] = append([]card.Card{c}, piles[i%k]...)
+	}
+
+	cards := make([]card.Card, 0, len(d.Cards))
+	for _, pile := range piles {
+		cards = append(cards, pile...)
+	}
+
+	d.Cards = cards
+	d.Shuffled = true
+	d.reordered()
+	d.record(Event{Type: EventShuffled, Method: "pile", Order: d.Cards})
+	return nil
+}
+
+// PerfectFaro shuffles the Deck once, as a perfect faro (weave) shuffle: the Deck is cut exactly in half, and the two
+// halves are interleaved one card at a time. An out-faro keeps the top card on top, while an in-faro (in is true)
+// moves it to the second position. Like PileShuffle, it does not use any randomness: 8 out-faros restore the order of
+// a standard deck of 52 cards. The Deck must have at least 2 remaining cards.
+func (d *Deck) PerfectFaro(in bool) error {
+	n := len(d.Cards)
+	if n < 2 {
+		return errors.New("a faro shuffle needs at least 2 cards")
+	}
+
+	// The first card of the shuffled Deck comes from the first half: the top half for an out-faro, and the bottom
+	// half for an in-faro. When n is odd, the first half holds the extra card.
+	half := (n + 1) / 2
+	if in {
+		half = n / 2
+	}
+	top, bottom := d.Cards[:half], d.Cards[half:]
+	first, second := top, bottom
+	if in {
+		first, second = bottom, top
+	}
+
+	cards := make([]card.Card, 0, n)
+	for i := 0; i < len(first); i++ {
+		cards = append(cards, first[i])
+		if i < len(second) {
+			cards = append(cards, second[i])
+		}
+	}
+
+	method := "out-faro"
+	if in {
+		method = "in-faro"
+	}
+	d.Cards = cards
+	d.Shuffled = true
+	d.reordered()
+	d.record(Event{Type: EventShuffled, Method: method, Order: d.Cards})
+	return nil
+}
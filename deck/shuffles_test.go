@@ -0,0 +1,199 @@
+package deck
+
+import (
+	"deck-of-cards/card"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// The statistical tests below use seeded decks, so they always see the same shuffles and can not fail by chance.
+const shuffleTrials = 2000
+
+// newSeededDeck returns a standard deck shuffled by a SeededShuffler created with the given seed.
+func newSeededDeck(seed int64) Deck {
+	deck := NewStandardDeck()
+	deck.SetShuffler(NewSeededShuffler(seed))
+	return deck
+}
+
+// originalPositions returns the position of each card in a new standard deck.
+func originalPositions() map[card.Card]int {
+	positions := make(map[card.Card]int)
+	for i, c := range NewStandardDeck().Cards {
+		positions[c] = i
+	}
+	return positions
+}
+
+// risingSequences returns the number of rising sequences of a shuffled standard deck: the maximal runs of cards which
+// kept their relative order from the new deck (e.g. 1, 2 and 3 in 1 4 2 5 3). One riffle shuffle makes at most two.
+func risingSequences(cards []card.Card) int {
+	positions := originalPositions()
+	shuffledPositions := make([]int, len(cards))
+	for i, c := range cards {
+		shuffledPositions[positions[c]] = i
+	}
+
+	sequences := 1
+	for i := 0; i+1 < len(shuffledPositions); i++ {
+		if shuffledPositions[i+1] < shuffledPositions[i] {
+			sequences++
+		}
+	}
+	return sequences
+}
+
+// topCardPositions shuffles shuffleTrials seeded decks with shuffle, and returns the average position the top card
+// ends up at, and how many times it ends up in each quarter of the deck.
+func topCardPositions(shuffle func(d *Deck)) (float64, [4]int) {
+	var sum float64
+	var quarters [4]int
+	for seed := 0; seed < shuffleTrials; seed++ {
+		deck := newSeededDeck(int64(seed))
+		top := deck.Cards[0]
+		shuffle(&deck)
+
+		position := indexOf(deck.Cards, top)
+		sum += float64(position)
+		quarters[position/13]++
+	}
+	return sum / shuffleTrials, quarters
+}
+
+func TestCut(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "KD", "AC", "2C", "KH"})
+
+	err := deck.Cut(2)
+	require.NoError(t, err)
+	expectedCards, _ := card.FromStrings([]string{"AC", "2C", "KH", "AS", "KD"})
+	assert.Equal(t, expectedCards, deck.Cards)
+	assert.Equal(t, 5, deck.Remaining)
+
+	for _, n := range []int{0, 5, -1} {
+		assert.ErrorIs(t, deck.Cut(n), ErrInvalidPosition, "can not cut at position %d", n)
+	}
+}
+
+func TestRiffleShuffle(t *testing.T) {
+	for seed := 0; seed < 100; seed++ {
+		deck := newSeededDeck(int64(seed))
+		deck.RiffleShuffle()
+
+		assert.ElementsMatch(t, NewStandardDeck().Cards, deck.Cards, "Shuffling keeps the same cards")
+		assert.LessOrEqual(t, risingSequences(deck.Cards), 2, "One riffle interleaves two packets")
+		assert.True(t, deck.Shuffled)
+	}
+
+	// After one riffle, the top card is still near the top: it is the first card of one of the two packets.
+	average, _ := topCardPositions(func(d *Deck) { d.RiffleShuffle() })
+	assert.Less(t, average, 3.0)
+
+	// After seven riffles, it is anywhere in the deck. Seven riffles are not quite a uniform shuffle: the top card
+	// still ends up in the top quarter of the deck about 29% of the time, instead of 25%.
+	average, quarters := topCardPositions(func(d *Deck) {
+		for i := 0; i < 7; i++ {
+			d.RiffleShuffle()
+		}
+	})
+	assert.InDelta(t, 25.5, average, 2.5)
+	for quarter, count := range quarters {
+		assert.InDelta(t, shuffleTrials/4, count, 150, "quarter %d should be about as likely as the others", quarter)
+	}
+
+	// Many riffles make as many rising sequences as a uniform shuffle (about n/2).
+	var sequences int
+	for seed := 0; seed < 200; seed++ {
+		deck := newSeededDeck(int64(seed))
+		for i := 0; i < 10; i++ {
+			deck.RiffleShuffle()
+		}
+		sequences += risingSequences(deck.Cards)
+	}
+	assert.InDelta(t, 26.5, float64(sequences)/200, 1.5)
+}
+
+func TestOverhandShuffle(t *testing.T) {
+	positions := originalPositions()
+
+	var packets int
+	for seed := 0; seed < shuffleTrials; seed++ {
+		deck := newSeededDeck(int64(seed))
+		deck.OverhandShuffle()
+		assert.ElementsMatch(t, NewStandardDeck().Cards, deck.Cards, "Shuffling keeps the same cards")
+
+		// Each packet keeps its cards in order, so a new packet starts wherever a card is not followed by the next
+		// card of the new deck.
+		packets++
+		for i := 0; i+1 < len(deck.Cards); i++ {
+			if positions[deck.Cards[i+1]] != positions[deck.Cards[i]]+1 {
+				packets++
+			}
+		}
+	}
+	// Each of the 51 gaps between cards starts a new packet with probability 1/overhandPacketSize.
+	assert.InDelta(t, 1+51.0/overhandPacketSize, float64(packets)/shuffleTrials, 0.5)
+
+	// The top card is in the first packet, which ends up at the bottom.
+	average, _ := topCardPositions(func(d *Deck) { d.OverhandShuffle() })
+	assert.Greater(t, average, 44.0)
+}
+
+func TestPileShuffle(t *testing.T) {
+	deck, _ := NewPartialDeck([]string{"AS", "2S", "3S", "4S", "5S", "6S", "7S"})
+
+	err := deck.PileShuffle(3)
+	require.NoError(t, err)
+	// The piles are AS 4S 7S, 2S 5S and 3S 6S, each dealt face down, so their order is reversed.
+	expectedCards, _ := card.FromStrings([]string{"7S", "4S", "AS", "5S", "2S", "6S", "3S"})
+	assert.Equal(t, expectedCards, deck.Cards)
+
+	// Cards which were next to each other are separated.
+	standardDeck := NewStandardDeck()
+	require.NoError(t, standardDeck.PileShuffle(4))
+	positions := originalPositions()
+	for i := 0; i+1 < len(standardDeck.Cards); i++ {
+		distance := positions[standardDeck.Cards[i+1]] - positions[standardDeck.Cards[i]]
+		assert.NotEqual(t, 1, distance, "%s and %s should be separated", standardDeck.Cards[i], standardDeck.Cards[i+1])
+	}
+
+	for _, k := range []int{0, 1, 8} {
+		assert.Error(t, deck.PileShuffle(k), "can not deal into %d piles", k)
+	}
+}
+
+func TestPerfectFaro(t *testing.T) {
+	newDeck := NewStandardDeck()
+
+	deck := NewStandardDeck()
+	require.NoError(t, deck.PerfectFaro(false))
+	assert.Equal(t, newDeck.Cards[0], deck.Cards[0], "An out-faro keeps the top card on top")
+	assert.Equal(t, newDeck.Cards[51], deck.Cards[51], "An out-faro keeps the bottom card at the bottom")
+	assert.Equal(t, newDeck.Cards[26], deck.Cards[1], "The halves are interleaved")
+	for i := 0; i < 7; i++ {
+		require.NoError(t, deck.PerfectFaro(false))
+	}
+	assert.Equal(t, newDeck.Cards, deck.Cards, "8 out-faros restore the order of the deck")
+
+	deck = NewStandardDeck()
+	require.NoError(t, deck.PerfectFaro(true))
+	assert.Equal(t, newDeck.Cards[0], deck.Cards[1], "An in-faro moves the top card to the second position")
+	for i := 1; i < 26; i++ {
+		require.NoError(t, deck.PerfectFaro(true))
+	}
+	for i := range newDeck.Cards {
+		assert.Equal(t, newDeck.Cards[i], deck.Cards[51-i], "26 in-faros reverse the order of the deck")
+	}
+
+	// Decks with an odd number of cards are cut with the extra card in the first half.
+	oddDeck, _ := NewPartialDeck([]string{"AS", "2S", "3S", "4S", "5S"})
+	require.NoError(t, oddDeck.PerfectFaro(false))
+	expectedCards, _ := card.FromStrings([]string{"AS", "4S", "2S", "5S", "3S"})
+	assert.Equal(t, expectedCards, oddDeck.Cards)
+	require.NoError(t, oddDeck.PerfectFaro(true))
+	expectedCards, _ = card.FromStrings([]string{"2S", "AS", "5S", "4S", "3S"})
+	assert.Equal(t, expectedCards, oddDeck.Cards)
+
+	singleCard, _ := NewPartialDeck([]string{"AS"})
+	assert.Error(t, singleCard.PerfectFaro(false))
+}
@@ -1,6 +1,6 @@
 // Package deck provides the Store type for managing multiple decks of cards in a
 // thread-safe manner. It offers methods for creating a new store, adding a deck,
-// getting a deck by its ID, and removing a deck by its ID.
+// getting a deck by its ID, updating a deck, and removing a deck by its ID.
 //
 // Example usage:
 //
@@ -9,31 +9,173 @@
 //	_ = s.Add(&d)
 //	retrievedDeck, _ := s.Get(d.ID)
 //	fmt.Println(retrievedDeck.ID == d.ID) // Output: true
+//	_ = s.Update(d.ID, func(d *deck.Deck) error { _, err := d.Draw(1); return err })
 //	_ = s.Remove(d.ID)
 package deck
 
 import (
+	"container/list"
 	"errors"
 	"github.com/google/uuid"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// Store manages a collection of decks in a thread-safe manner. Decks are accessed by their ID (uuid).
+// ErrStoreFull is returned by Store.Add when adding the deck would exceed the capacity of the Store, and the Store
+// rejects new decks when full (see StoreConfig).
+var ErrStoreFull = errors.New("deck store is full")
+
+// DefaultJanitorInterval is how often a Store evicts its expired decks, unless configured otherwise.
+const DefaultJanitorInterval = time.Minute
+
+// DefaultExpiredRetention is how long a Store remembers the IDs of its expired decks, unless configured otherwise.
+const DefaultExpiredRetention = 24 * time.Hour
+
+// FullPolicy tells what a Store does when adding a deck would exceed its capacity.
+type FullPolicy int
+
+const (
+	// RejectWhenFull makes Store.Add return ErrStoreFull.
+	RejectWhenFull FullPolicy = iota
+	// EvictLeastRecentlyUsed makes Store.Add evict the least recently used decks until the new deck fits.
+	EvictLeastRecentlyUsed
+)
+
+// StoreConfig configures a Store created by NewStoreWithConfig. The zero value keeps any number of decks forever.
+type StoreConfig struct {
+	// IdleTTL is how long a deck is kept without being accessed (by Get or Update). Zero means forever.
+	IdleTTL time.Duration
+	// MaxAge is how long a deck is kept after being added, even if it is still being used. Zero means forever.
+	MaxAge time.Duration
+	// JanitorInterval is how often expired decks are evicted in the background. Defaults to DefaultJanitorInterval.
+	JanitorInterval time.Duration
+	// ExpiredRetention is how long the IDs of expired decks are remembered, so accessing them returns ErrDeckExpired
+	// instead of ErrDeckNotFound. Defaults to DefaultExpiredRetention.
+	ExpiredRetention time.Duration
+
+	// MaxDecks is the maximum number of decks in the Store. Zero means unlimited.
+	MaxDecks int
+	// MaxTotalCards is the maximum number of cards (see Deck.Size) of all the decks in the Store, checked when a deck
+	// is added. Zero means unlimited.
+	MaxTotalCards int
+	// WhenFull tells what happens when adding a deck would exceed MaxDecks or MaxTotalCards.
+	WhenFull FullPolicy
+}
+
+// StoreStats holds the usage counters of a Store.
+type StoreStats struct {
+	// Decks is the number of decks in the Store.
+	Decks int
+	// Cards is the number of cards of all the decks in the Store.
+	Cards int
+	// Evicted is the number of decks evicted to make room for new decks (see EvictLeastRecentlyUsed).
+	Evicted uint64
+	// Expired is the number of decks which expired.
+	Expired uint64
+	// Rejected is the number of decks which were not added because the Store was full (see RejectWhenFull).
+	Rejected uint64
+}
+
+// Store manages a collection of decks in memory, in a thread-safe manner. Decks are accessed by their ID (uuid).
+// It implements DeckRepository. Decks are lost when the process stops (see BoltStore).
+//
+// Each deck has its own lock, so operations on a deck are linearizable, while operations on different decks do not
+// wait for each other.
+//
+// Decks may expire (see StoreConfig). Expired decks can not be accessed anymore, and are evicted by a background
+// janitor, which must be stopped with Close.
+//
+// The capacity of a Store may be limited (see StoreConfig). When full, it either rejects new decks, or evicts the
+// least recently used ones.
 type Store struct {
-	decks map[uuid.UUID]*Deck
+	decks map[uuid.UUID]*storeEntry
+	// expired holds the IDs of the expired decks, and when they expired.
+	expired map[uuid.UUID]time.Time
 	// Maps are not safe for concurrent access, so we will synchronize with a Mutex.
 	// It is OK to have concurrent reads though, se we use RWMutex.
+	// mu only protects the maps: the decks themselves are protected by the lock of their entry.
 	mu sync.RWMutex
+
+	// recent holds the entries from the most recently used to the least recently used. It is protected by recentMu,
+	// which must not be held while acquiring any other lock.
+	recent   *list.List
+	recentMu sync.Mutex
+
+	// cards is the number of cards of all the decks in the Store.
+	cards    atomic.Int64
+	evicted  atomic.Uint64
+	expiries atomic.Uint64
+	rejected atomic.Uint64
+
+	config StoreConfig
+	// now returns the current time. It is replaced in tests.
+	now       func() time.Time
+	stop      chan struct{}
+	closeOnce sync.Once
 }
 
-// NewStore creates and returns a new instance of the Store type.
+// storeEntry holds a deck of a Store, and the lock serializing the operations on it.
+type storeEntry struct {
+	mu   sync.Mutex
+	deck *Deck
+	// archive holds the events moved out of the History of the deck, in order (see EventCompacted).
+	archive []Event
+	// addedAt and accessedAt are when the deck was added to the Store, and last accessed.
+	addedAt    time.Time
+	accessedAt time.Time
+	// element is the element of the entry in the recent list of the Store.
+	element *list.Element
+	// gone is set when the entry is removed from the Store (ErrDeckNotFound) or expires (ErrDeckExpired), for
+	// operations that were waiting for its lock.
+	gone error
+}
+
+// NewStore creates and returns a new instance of the Store type. Its decks never expire.
 func NewStore() *Store {
-	return &Store{
-		decks: make(map[uuid.UUID]*Deck),
+	return NewStoreWithConfig(StoreConfig{})
+}
+
+// NewStoreWithConfig creates and returns a new Store configured by config. If its decks expire, a background janitor
+// evicts them until the Store is closed.
+func NewStoreWithConfig(config StoreConfig) *Store {
+	return newStore(config, time.Now)
+}
+
+// newStore creates a Store which uses now to tell the current time.
+func newStore(config StoreConfig, now func() time.Time) *Store {
+	if config.JanitorInterval <= 0 {
+		config.JanitorInterval = DefaultJanitorInterval
 	}
+	if config.ExpiredRetention <= 0 {
+		config.ExpiredRetention = DefaultExpiredRetention
+	}
+
+	s := &Store{
+		decks:   make(map[uuid.UUID]*storeEntry),
+		expired: make(map[uuid.UUID]time.Time),
+		recent:  list.New(),
+		config:  config,
+		now:     now,
+		stop:    make(chan struct{}),
+	}
+	if config.IdleTTL > 0 || config.MaxAge > 0 {
+		go s.janitor()
+	}
+	return s
 }
 
-// Add adds a new deck to the store. It returns an error if a deck with the same ID already exists in the store.
+// Close stops the background janitor of the Store. The Store can still be used, but expired decks are only evicted
+// when they are accessed.
+func (s *Store) Close() error {
+	s.closeOnce.Do(func() { close(s.stop) })
+	return nil
+}
+
+// Add adds a new deck to the store. It returns an error if a deck with the same ID already exists in the store, or
+// ErrStoreFull if there is no room for the deck (see StoreConfig).
+// The store keeps its own copy of the deck: later changes to deck are not saved (see Update).
+// Add sets the ExpiresAt of deck.
 func (s *Store) Add(deck *Deck) error {
 	if deck == nil {
 		return errors.New("deck pointer can not be nil")
@@ -50,18 +192,161 @@ func (s *Store) Add(deck *Deck) error {
 		return errors.New("deck ID already exists in the store")
 	}
 
-	s.decks[deck.ID] = deck
+	if err := s.makeRoom(deck.Size()); err != nil {
+		s.rejected.Add(1)
+		return err
+	}
+
+	now := s.now()
+	entry := &storeEntry{deck: deck.Clone(), addedAt: now, accessedAt: now}
+	deck.ExpiresAt = s.expiresAt(entry)
+	s.decks[deck.ID] = entry
+	s.cards.Add(int64(deck.Size()))
+	s.recentMu.Lock()
+	entry.element = s.recent.PushFront(entry)
+	s.recentMu.Unlock()
 	return nil
 }
 
-// Get retrieves a deck from the store by its ID. It returns the deck and nil if the deck is found, or nil and an error if the deck is not found.
-func (s *Store) Get(id uuid.UUID) (*Deck, error) {
+// List returns copies of a page of the decks matching filter, from the oldest to the newest, and the cursor of the
+// next page (see DeckRepository). Listing decks does not count as using them.
+func (s *Store) List(filter DeckFilter, page Page) ([]*Deck, string, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	if deck, ok := s.decks[id]; ok {
-		return deck, nil
+
+	now := s.now()
+	var decks []*Deck
+	for _, entry := range s.decks {
+		entry.mu.Lock()
+		if entry.gone == nil && !s.isExpired(entry, now) && filter.matches(entry.deck) {
+			deck := entry.deck.Clone()
+			deck.ExpiresAt = s.expiresAt(entry)
+			decks = append(decks, deck)
+		}
+		entry.mu.Unlock()
+	}
+
+	sortDecks(decks)
+	return paginate(decks, page)
+}
+
+// Stats returns the usage counters of the Store.
+func (s *Store) Stats() StoreStats {
+	s.mu.RLock()
+	decks := len(s.decks)
+	s.mu.RUnlock()
+
+	return StoreStats{
+		Decks:    decks,
+		Cards:    int(s.cards.Load()),
+		Evicted:  s.evicted.Load(),
+		Expired:  s.expiries.Load(),
+		Rejected: s.rejected.Load(),
+	}
+}
+
+// makeRoom makes sure a deck of the given size can be added to the store, evicting the least recently used decks if
+// the store is configured to. It returns ErrStoreFull if there is no room for the deck. s.mu must be locked.
+func (s *Store) makeRoom(size int) error {
+	if s.config.MaxTotalCards > 0 && size > s.config.MaxTotalCards {
+		return ErrStoreFull
+	}
+
+	for s.isFull(size) {
+		if s.config.WhenFull != EvictLeastRecentlyUsed {
+			return ErrStoreFull
+		}
+
+		s.recentMu.Lock()
+		last := s.recent.Back()
+		s.recentMu.Unlock()
+		if last == nil {
+			return ErrStoreFull
+		}
+
+		entry := last.Value.(*storeEntry)
+		// Waits for the operation in progress on the deck (if any) to finish.
+		entry.mu.Lock()
+		entry.gone = ErrDeckNotFound
+		s.delete(entry)
+		entry.mu.Unlock()
+		s.evicted.Add(1)
+	}
+	return nil
+}
+
+// isFull tells whether adding a deck of the given size would exceed the capacity of the store. s.mu must be locked.
+func (s *Store) isFull(size int) bool {
+	if s.config.MaxDecks > 0 && len(s.decks)+1 > s.config.MaxDecks {
+		return true
+	}
+	return s.config.MaxTotalCards > 0 && int(s.cards.Load())+size > s.config.MaxTotalCards
+}
+
+// delete removes entry from the store. s.mu and the entry must be locked.
+func (s *Store) delete(entry *storeEntry) {
+	delete(s.decks, entry.deck.ID)
+	s.cards.Add(-int64(entry.deck.Size()))
+	s.recentMu.Lock()
+	s.recent.Remove(entry.element)
+	s.recentMu.Unlock()
+}
+
+// Get retrieves a copy of a deck from the store by its ID. It returns the deck and nil if the deck is found, or nil and
+// ErrDeckNotFound (or ErrDeckExpired) if the deck is not found. Changes to the returned deck are not saved (see
+// Update).
+func (s *Store) Get(id uuid.UUID) (*Deck, error) {
+	entry, err := s.lock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer entry.mu.Unlock()
+
+	deck := entry.deck.Clone()
+	deck.ExpiresAt = s.expiresAt(entry)
+	return deck, nil
+}
+
+// Update calls fn with a copy of the deck with the given ID, and replaces the stored deck with it if fn succeeds.
+// Updates of the same deck are serialized, so concurrent updates never see (or overwrite) each other's changes
+// halfway through.
+// It returns ErrDeckNotFound (or ErrDeckExpired) if the deck is not found, or the error returned by fn.
+func (s *Store) Update(id uuid.UUID, fn func(*Deck) error) error {
+	entry, err := s.lock(id)
+	if err != nil {
+		return err
+	}
+	defer entry.mu.Unlock()
+
+	// fn may fail halfway through its changes. Working on a copy keeps the stored deck consistent.
+	updated := entry.deck.Clone()
+	updated.ExpiresAt = s.expiresAt(entry)
+	if err := fn(updated); err != nil {
+		return err
+	}
+	if updated.ID != id {
+		return errors.New("the ID of a deck can not be updated")
+	}
+	updated.bumpVersion(entry.deck.Version, len(entry.deck.History))
+	entry.archive = append(entry.archive, updated.compactHistory()...)
+
+	// fn may keep a reference to the deck it was given, so the store keeps its own copy.
+	s.cards.Add(int64(updated.Size() - entry.deck.Size()))
+	entry.deck = updated.Clone()
+	return nil
+}
+
+// ArchivedHistory returns the events moved out of the History of the deck with the given ID whose Sequence is greater
+// than after, at most limit of them unless limit is zero. It returns ErrDeckNotFound (or ErrDeckExpired) if the deck is
+// not found.
+func (s *Store) ArchivedHistory(id uuid.UUID, after int, limit int) ([]Event, error) {
+	entry, err := s.lock(id)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("deck not found")
+	defer entry.mu.Unlock()
+
+	return pageEvents(entry.archive, after, limit), nil
 }
 
 // Remove removes a deck from the store by its ID. It returns an error if the deck is not found.
@@ -69,11 +354,157 @@ func (s *Store) Remove(deckID uuid.UUID) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	_, exists := s.decks[deckID]
+	entry, exists := s.decks[deckID]
 	if !exists {
-		return errors.New("deck not found")
+		if _, expired := s.expired[deckID]; expired {
+			return ErrDeckExpired
+		}
+		return ErrDeckNotFound
 	}
 
-	delete(s.decks, deckID)
+	// Waits for the operation in progress on the deck (if any) to finish.
+	entry.mu.Lock()
+	entry.gone = ErrDeckNotFound
+	s.delete(entry)
+	entry.mu.Unlock()
 	return nil
 }
+
+// RemoveMatching removes every deck matching filter from the store, and returns how many decks were removed.
+// Expired decks are not counted.
+func (s *Store) RemoveMatching(filter DeckFilter) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	removed := 0
+	for _, entry := range s.decks {
+		// Waits for the operation in progress on the deck (if any) to finish.
+		entry.mu.Lock()
+		if entry.gone == nil && !s.isExpired(entry, now) && filter.matches(entry.deck) {
+			entry.gone = ErrDeckNotFound
+			s.delete(entry)
+			removed++
+		}
+		entry.mu.Unlock()
+	}
+	return removed, nil
+}
+
+// lock returns the entry of the deck with the given ID, locked, and marks the deck as accessed. It returns
+// ErrDeckNotFound if the deck is not found, or ErrDeckExpired if it expired. The caller must unlock the entry.
+func (s *Store) lock(id uuid.UUID) (*storeEntry, error) {
+	s.mu.RLock()
+	entry, ok := s.decks[id]
+	_, expired := s.expired[id]
+	s.mu.RUnlock()
+	if expired {
+		return nil, ErrDeckExpired
+	}
+	if !ok {
+		return nil, ErrDeckNotFound
+	}
+
+	entry.mu.Lock()
+	// The deck may have been removed while waiting for the lock.
+	if entry.gone != nil {
+		entry.mu.Unlock()
+		return nil, entry.gone
+	}
+
+	now := s.now()
+	if s.isExpired(entry, now) {
+		// The janitor has not evicted the deck yet.
+		entry.gone = ErrDeckExpired
+		entry.mu.Unlock()
+		s.evict(id, entry, now)
+		return nil, ErrDeckExpired
+	}
+
+	entry.accessedAt = now
+	s.recentMu.Lock()
+	s.recent.MoveToFront(entry.element)
+	s.recentMu.Unlock()
+	return entry, nil
+}
+
+// expiresAt returns when the deck of entry expires, if it is not accessed until then. It returns the zero time if the
+// deck never expires. The entry must be locked.
+func (s *Store) expiresAt(entry *storeEntry) time.Time {
+	var expiresAt time.Time
+	if s.config.IdleTTL > 0 {
+		expiresAt = entry.accessedAt.Add(s.config.IdleTTL)
+	}
+	if s.config.MaxAge > 0 {
+		maxAgeAt := entry.addedAt.Add(s.config.MaxAge)
+		if expiresAt.IsZero() || maxAgeAt.Before(expiresAt) {
+			expiresAt = maxAgeAt
+		}
+	}
+	return expiresAt
+}
+
+// isExpired tells whether the deck of entry is expired at the given time. The entry must be locked.
+func (s *Store) isExpired(entry *storeEntry, now time.Time) bool {
+	expiresAt := s.expiresAt(entry)
+	return !expiresAt.IsZero() && !now.Before(expiresAt)
+}
+
+// evict removes the expired entry of the deck with the given ID from the store, and remembers that the deck expired.
+// The entry must not be locked.
+func (s *Store) evict(id uuid.UUID, entry *storeEntry, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The entry may have been evicted in the meantime.
+	if s.decks[id] == entry {
+		entry.mu.Lock()
+		s.delete(entry)
+		entry.mu.Unlock()
+		s.expired[id] = now
+		s.expiries.Add(1)
+	}
+}
+
+// janitor evicts the expired decks every JanitorInterval, until the Store is closed.
+func (s *Store) janitor() {
+	ticker := time.NewTicker(s.config.JanitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.evictExpired()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// evictExpired removes the expired decks from the store, and forgets the decks which expired more than
+// ExpiredRetention ago.
+func (s *Store) evictExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, entry := range s.decks {
+		// A deck which is being used is not idle. If it reached its MaxAge, it is evicted on the next run.
+		if !entry.mu.TryLock() {
+			continue
+		}
+		if s.isExpired(entry, now) {
+			entry.gone = ErrDeckExpired
+			s.delete(entry)
+			s.expired[id] = now
+			s.expiries.Add(1)
+		}
+		entry.mu.Unlock()
+	}
+
+	for id, expiredAt := range s.expired {
+		if now.Sub(expiredAt) >= s.config.ExpiredRetention {
+			delete(s.expired, id)
+		}
+	}
+}
@@ -1,97 +1,547 @@
 package deck
 
 import (
+	"deck-of-cards/card"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 )
 
-// These tests are not very relevant as of now.
-// `Store` is just a thin layer wrapping a `map`, and we can rely on `map` working correctly.
-// The tests here would be useful if we were to implement multiple versions of a `Store` later, such
-// as one using Redis.
+// Every DeckRepository implementation should behave the same, so these tests run against all of them.
+// See forEachRepository.
+
+// repositories returns a constructor for each DeckRepository implementation, by name.
+func repositories() map[string]func(t *testing.T) DeckRepository {
+	return map[string]func(t *testing.T) DeckRepository{
+		"memory": func(t *testing.T) DeckRepository {
+			return NewStore()
+		},
+		"bolt": func(t *testing.T) DeckRepository {
+			store, err := NewBoltStore(filepath.Join(t.TempDir(), "decks.db"))
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = store.Close() })
+			return store
+		},
+	}
+}
+
+// forEachRepository runs test as a subtest for each DeckRepository implementation, with a new, empty repository.
+func forEachRepository(t *testing.T, test func(t *testing.T, store DeckRepository)) {
+	for name, newRepository := range repositories() {
+		t.Run(name, func(t *testing.T) {
+			test(t, newRepository(t))
+		})
+	}
+}
 
 func TestStoreAddDeck(t *testing.T) {
-	store := NewStore()
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		deck := NewStandardDeck()
+		err := store.Add(&deck)
+		require.NoError(t, err)
 
-	deck := NewStandardDeck()
-	err := store.Add(&deck)
-	require.NoError(t, err)
+		retrievedDeck, err := store.Get(deck.ID)
+		require.NoError(t, err)
+		assert.Equal(t, deck, *retrievedDeck)
+	})
+}
 
-	retrievedDeck, err := store.Get(deck.ID)
-	require.NoError(t, err)
-	assert.Equal(t, deck, *retrievedDeck)
+func TestStoreReturnsCopies(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		deck := NewStandardDeck()
+		deck.Metadata = map[string]string{"game": "poker"}
+		err := store.Add(&deck)
+		require.NoError(t, err)
+
+		// Changes to decks outside of Update are not saved.
+		_, _ = deck.Draw(1)
+		retrievedDeck, err := store.Get(deck.ID)
+		require.NoError(t, err)
+		_, _ = retrievedDeck.Draw(1)
+		retrievedDeck.Metadata["game"] = "bridge"
+
+		retrievedDeck, err = store.Get(deck.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 52, retrievedDeck.Remaining)
+		assert.Equal(t, map[string]string{"game": "poker"}, retrievedDeck.Metadata)
+	})
 }
 
 func TestStoreAddMultipleDecks(t *testing.T) {
-	store := NewStore()
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		decks := []Deck{NewStandardDeck(), NewStandardDeck(), NewStandardDeck()}
+		for _, deck := range decks {
+			err := store.Add(&deck)
+			require.NoError(t, err)
+		}
 
-	decks := []Deck{NewStandardDeck(), NewStandardDeck(), NewStandardDeck()}
-	for _, deck := range decks {
+		// All the decks have been correctly inserted.
+		for _, deck := range decks {
+			_, err := store.Get(deck.ID)
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestStoreAddDeckWithDuplicateID(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		deck1 := NewStandardDeck()
+		deck2 := NewStandardDeck()
+
+		// Set the ID of deck2 to be the same as deck1
+		deck2.ID = deck1.ID
+
+		err := store.Add(&deck1)
+		require.NoError(t, err)
+
+		err = store.Add(&deck2)
+		assert.Error(t, err, "Adding a deck with a duplicate ID should return an error")
+	})
+}
+
+func TestStoreAddNilDeck(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		err := store.Add(nil)
+		assert.Error(t, err, "Adding a nil deck should return an error")
+	})
+}
+
+func TestStoreGetNonExistentDeck(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		nonExistentID := uuid.New()
+		_, err := store.Get(nonExistentID)
+		assert.ErrorIs(t, err, ErrDeckNotFound)
+	})
+}
+
+func TestStoreRemoveDeck(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		deck := NewStandardDeck()
 		err := store.Add(&deck)
 		require.NoError(t, err)
-	}
 
-	// All the decks have been correctly inserted.
-	for _, deck := range decks {
-		_, err := store.Get(deck.ID)
+		err = store.Remove(deck.ID)
 		require.NoError(t, err)
-	}
+
+		_, err = store.Get(deck.ID)
+		assert.ErrorIs(t, err, ErrDeckNotFound)
+	})
 }
 
-func TestStoreAddDeckWithDuplicateID(t *testing.T) {
-	store := NewStore()
+func TestStoreRemoveNonExistentDeck(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		nonExistentID := uuid.New()
+		err := store.Remove(nonExistentID)
+		assert.ErrorIs(t, err, ErrDeckNotFound)
+	})
+}
 
-	deck1 := NewStandardDeck()
-	deck2 := NewStandardDeck()
+func TestStoreUpdateDeck(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		deck := NewStandardDeck(WithJokers())
+		err := store.Add(&deck)
+		require.NoError(t, err)
 
-	// Set the ID of deck2 to be the same as deck1
-	deck2.ID = deck1.ID
+		err = store.Update(deck.ID, func(d *Deck) error {
+			if _, err := d.DrawToPile("alice", 2); err != nil {
+				return err
+			}
+			_, err := d.Draw(3)
+			return err
+		})
+		require.NoError(t, err)
 
-	err := store.Add(&deck1)
-	require.NoError(t, err)
+		retrievedDeck, err := store.Get(deck.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 49, retrievedDeck.Remaining)
+		assert.Len(t, retrievedDeck.Cards, 49)
+		assert.Len(t, retrievedDeck.Drawn, 3)
+		require.Contains(t, retrievedDeck.Piles, "alice")
+		assert.Len(t, retrievedDeck.Piles["alice"].Cards, 2)
+		assert.Equal(t, uint64(1), retrievedDeck.Version, "Each update increments the version of the deck")
+	})
+}
+
+func TestStoreUpdateDeckFails(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		deck := NewStandardDeck()
+		err := store.Add(&deck)
+		require.NoError(t, err)
 
-	err = store.Add(&deck2)
-	assert.Error(t, err, "Adding a deck with a duplicate ID should return an error")
+		errUpdate := errors.New("update failed")
+		err = store.Update(deck.ID, func(d *Deck) error {
+			_, _ = d.Draw(5)
+			return errUpdate
+		})
+		assert.ErrorIs(t, err, errUpdate)
+
+		retrievedDeck, err := store.Get(deck.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 52, retrievedDeck.Remaining, "Changes of a failed update are not saved")
+		assert.Zero(t, retrievedDeck.Version, "Failed updates do not change the version of the deck")
+
+		err = store.Update(deck.ID, func(d *Deck) error {
+			d.ID = uuid.New()
+			return nil
+		})
+		assert.Error(t, err, "The ID of a deck can not be updated")
+	})
 }
 
-func TestStoreAddNilDeck(t *testing.T) {
-	store := NewStore()
+func TestStoreUpdateNonExistentDeck(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		called := false
+		err := store.Update(uuid.New(), func(d *Deck) error {
+			called = true
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrDeckNotFound)
+		assert.False(t, called)
+	})
+}
+
+func TestStoreKeepsSeededShuffler(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		deck := NewStandardDeck()
+		deck.SetShuffler(NewSeededShuffler(42))
+		deck.Shuffle()
+		err := store.Add(&deck)
+		require.NoError(t, err)
 
-	err := store.Add(nil)
-	assert.Error(t, err, "Adding a nil deck should return an error")
+		err = store.Update(deck.ID, func(d *Deck) error {
+			d.Shuffle()
+			return nil
+		})
+		require.NoError(t, err)
+
+		// The stored deck keeps shuffling as a deck that was never stored.
+		expected := NewStandardDeck()
+		expected.SetShuffler(NewSeededShuffler(42))
+		expected.Shuffle()
+		expected.Shuffle()
+
+		retrievedDeck, err := store.Get(deck.ID)
+		require.NoError(t, err)
+		assert.Equal(t, expected.Cards, retrievedDeck.Cards)
+	})
 }
 
-func TestStoreGetNonExistentDeck(t *testing.T) {
-	store := NewStore()
+func TestStoreKeepsHistory(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		deck := NewStandardDeck()
+		deck.SetActor("dealer")
+		deck.Shuffle()
+		err := store.Add(&deck)
+		require.NoError(t, err)
+
+		err = store.Update(deck.ID, func(d *Deck) error {
+			d.SetActor("alice")
+			_, err := d.DrawToPile("alice", 2)
+			return err
+		})
+		require.NoError(t, err)
+		err = store.Update(deck.ID, func(d *Deck) error {
+			_, err := d.Draw(1)
+			return err
+		})
+		require.NoError(t, err)
 
-	nonExistentID := uuid.New()
-	_, err := store.Get(nonExistentID)
-	assert.Error(t, err)
+		retrievedDeck, err := store.Get(deck.ID)
+		require.NoError(t, err)
+		require.Len(t, retrievedDeck.History, 4)
+		assert.Equal(t, "dealer", retrievedDeck.History[1].Actor)
+		assert.Equal(t, "alice", retrievedDeck.History[2].Actor)
+		assert.Empty(t, retrievedDeck.History[3].Actor, "The actor is not saved with the deck")
+		assert.NoError(t, retrievedDeck.VerifyHistory())
+	})
 }
 
-func TestStoreRemoveDeck(t *testing.T) {
-	store := NewStore()
+func TestBoltStorePersistsDecks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decks.db")
 
+	store, err := NewBoltStore(path)
+	require.NoError(t, err)
 	deck := NewStandardDeck()
-	err := store.Add(&deck)
+	require.NoError(t, deck.CommitShuffle("client seed"))
+	_, err = deck.IssueOwnerToken()
 	require.NoError(t, err)
+	require.NoError(t, store.Add(&deck))
+	require.NoError(t, store.Close())
 
-	err = store.Remove(deck.ID)
+	// Decks survive closing and reopening the store (e.g. a restart of the server).
+	store, err = NewBoltStore(path)
 	require.NoError(t, err)
+	defer store.Close()
 
-	_, err = store.Get(deck.ID)
-	assert.Error(t, err)
+	retrievedDeck, err := store.Get(deck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, deck, *retrievedDeck)
 }
 
-func TestStoreRemoveNonExistentDeck(t *testing.T) {
-	store := NewStore()
+func TestStoreListDecks(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		now := creationTime()
+		decks := []Deck{NewStandardDeck(), NewStandardDeck(), NewStandardDeck()}
+		// Added out of order, to check that decks are listed from the oldest to the newest.
+		decks[0].CreatedAt = now.Add(-2 * time.Hour)
+		decks[1].CreatedAt = now
+		decks[2].CreatedAt = now.Add(-time.Hour)
+		for i := range decks {
+			require.NoError(t, store.Add(&decks[i]))
+		}
+
+		listed, _, err := store.List(DeckFilter{}, Page{})
+		require.NoError(t, err)
+		require.Len(t, listed, 3)
+		assert.Equal(t, decks[0].ID, listed[0].ID)
+		assert.Equal(t, decks[2].ID, listed[1].ID)
+		assert.Equal(t, decks[1].ID, listed[2].ID)
+
+		listed, _, err = store.List(DeckFilter{CreatedBefore: now.Add(-30 * time.Minute)}, Page{})
+		require.NoError(t, err)
+		require.Len(t, listed, 2)
+		assert.Equal(t, decks[0].ID, listed[0].ID)
+		assert.Equal(t, decks[2].ID, listed[1].ID)
+
+		require.NoError(t, store.Remove(decks[0].ID))
+		listed, _, err = store.List(DeckFilter{CreatedBefore: now.Add(-30 * time.Minute)}, Page{})
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, decks[2].ID, listed[0].ID)
+	})
+}
+
+func TestStoreRemoveMatching(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		now := creationTime()
+		decks := []Deck{NewStandardDeck(), NewStandardDeck(), NewStandardDeck()}
+		decks[0].CreatedAt = now.Add(-2 * time.Hour)
+		decks[1].CreatedAt = now
+		decks[2].CreatedAt = now.Add(-time.Hour)
+		for i := range decks {
+			require.NoError(t, store.Add(&decks[i]))
+		}
+
+		removed, err := store.RemoveMatching(DeckFilter{CreatedBefore: now.Add(-30 * time.Minute)})
+		require.NoError(t, err)
+		assert.Equal(t, 2, removed)
+
+		_, err = store.Get(decks[0].ID)
+		assert.ErrorIs(t, err, ErrDeckNotFound)
+		_, err = store.Get(decks[2].ID)
+		assert.ErrorIs(t, err, ErrDeckNotFound)
+		_, err = store.Get(decks[1].ID)
+		assert.NoError(t, err, "Decks not matching the filter are kept")
+
+		removed, err = store.RemoveMatching(DeckFilter{CreatedBefore: now.Add(-30 * time.Minute)})
+		require.NoError(t, err)
+		assert.Zero(t, removed)
+	})
+}
+
+func TestStoreArchivedHistory(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		deck := NewStandardDeck()
+		require.NoError(t, store.Add(&deck))
+
+		archived, err := store.ArchivedHistory(deck.ID, 0, 0)
+		require.NoError(t, err)
+		assert.Empty(t, archived, "Nothing is archived until the history grows too long")
+
+		// Each update records 100 events, so the history grows too long after maxHistory/100 updates.
+		for i := 0; i <= maxHistory/100; i++ {
+			require.NoError(t, store.Update(deck.ID, func(d *Deck) error {
+				for j := 0; j < 100; j++ {
+					if err := d.Cut(1); err != nil {
+						return err
+					}
+				}
+				return nil
+			}))
+		}
+		retrieved, err := store.Get(deck.ID)
+		require.NoError(t, err)
+		require.Equal(t, EventCompacted, retrieved.History[0].Type)
+
+		// Every event moved out of the history is archived, in order.
+		archived, err = store.ArchivedHistory(deck.ID, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, archived, retrieved.History[0].Sequence)
+		for i, event := range archived {
+			assert.Equal(t, i+1, event.Sequence)
+		}
+		assert.Equal(t, EventCreated, archived[0].Type)
+		assert.Equal(t, retrieved.History[1].Sequence-1, archived[len(archived)-1].Sequence)
+
+		page, err := store.ArchivedHistory(deck.ID, 10, 5)
+		require.NoError(t, err)
+		assert.Equal(t, archived[10:15], page)
+
+		require.NoError(t, store.Remove(deck.ID))
+		_, err = store.ArchivedHistory(deck.ID, 0, 0)
+		assert.ErrorIs(t, err, ErrDeckNotFound)
+	})
+}
+
+func TestStoreListDecksWithFilter(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		now := creationTime()
+
+		alice := NewStandardDeck()
+		alice.Owner = "alice"
+		alice.CreatedAt = now.Add(-2 * time.Hour)
+		alice.Shuffle()
+		aliceToken, err := alice.IssueOwnerToken()
+		require.NoError(t, err)
+
+		aliceShoe, err := NewShoe(6)
+		require.NoError(t, err)
+		aliceShoe.Owner = "alice"
+		aliceShoe.CreatedAt = now.Add(-time.Hour)
+		aliceShoe.SetOwnerToken(aliceToken)
+
+		bob, err := NewPartialDeck([]string{"AS", "KD", "QH"})
+		require.NoError(t, err)
+		bob.Owner = "bob"
+		bob.CreatedAt = now
+
+		for _, d := range []*Deck{&alice, &aliceShoe, &bob} {
+			require.NoError(t, store.Add(d))
+		}
+
+		yes := true
+		ten, fiftyTwo := 10, 52
+		testCases := []struct {
+			name     string
+			filter   DeckFilter
+			expected []uuid.UUID
+		}{
+			{
+				name:     "owner",
+				filter:   DeckFilter{Owner: "alice"},
+				expected: []uuid.UUID{alice.ID, aliceShoe.ID},
+			},
+			{
+				name:     "unknown owner",
+				filter:   DeckFilter{Owner: "carol"},
+				expected: nil,
+			},
+			{
+				name:     "owner token",
+				filter:   DeckFilter{OwnerToken: aliceToken},
+				expected: []uuid.UUID{alice.ID, aliceShoe.ID},
+			},
+			{
+				name:     "unknown owner token",
+				filter:   DeckFilter{OwnerToken: "not-a-token"},
+				expected: nil,
+			},
+			{
+				name:     "shuffled",
+				filter:   DeckFilter{Shuffled: &yes},
+				expected: []uuid.UUID{alice.ID},
+			},
+			{
+				name:     "minimum remaining",
+				filter:   DeckFilter{MinRemaining: &fiftyTwo},
+				expected: []uuid.UUID{alice.ID, aliceShoe.ID},
+			},
+			{
+				name:     "remaining range",
+				filter:   DeckFilter{MinRemaining: &ten, MaxRemaining: &fiftyTwo},
+				expected: []uuid.UUID{alice.ID},
+			},
+			{
+				name:     "created after",
+				filter:   DeckFilter{CreatedAfter: now.Add(-90 * time.Minute)},
+				expected: []uuid.UUID{aliceShoe.ID, bob.ID},
+			},
+			{
+				name:     "several filters",
+				filter:   DeckFilter{Owner: "alice", CreatedAfter: now.Add(-90 * time.Minute)},
+				expected: []uuid.UUID{aliceShoe.ID},
+			},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				listed, next, err := store.List(tc.filter, Page{})
+				require.NoError(t, err)
+				assert.Empty(t, next)
+
+				var ids []uuid.UUID
+				for _, d := range listed {
+					ids = append(ids, d.ID)
+				}
+				assert.Equal(t, tc.expected, ids)
+			})
+		}
+	})
+}
+
+func TestStoreListDecksByPage(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		now := creationTime()
+		var ids []uuid.UUID
+		for i := 0; i < 5; i++ {
+			deck := NewStandardDeck()
+			deck.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, store.Add(&deck))
+			ids = append(ids, deck.ID)
+		}
+
+		first, next, err := store.List(DeckFilter{}, Page{Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, first, 2)
+		assert.Equal(t, ids[0], first[0].ID)
+		assert.Equal(t, ids[1], first[1].ID)
+		require.NotEmpty(t, next)
+
+		// Removing a deck of a previous page does not shift the next pages.
+		require.NoError(t, store.Remove(ids[0]))
+
+		second, next, err := store.List(DeckFilter{}, Page{Cursor: next, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, second, 2)
+		assert.Equal(t, ids[2], second[0].ID)
+		assert.Equal(t, ids[3], second[1].ID)
+		require.NotEmpty(t, next)
+
+		last, next, err := store.List(DeckFilter{}, Page{Cursor: next, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, last, 1)
+		assert.Equal(t, ids[4], last[0].ID)
+		assert.Empty(t, next, "There are no more decks after the last page")
+
+		_, _, err = store.List(DeckFilter{}, Page{Cursor: "not-a-cursor", Limit: 2})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
 
-	nonExistentID := uuid.New()
-	err := store.Remove(nonExistentID)
-	assert.Error(t, err)
+func TestStoreListDoesNotKeepDecksAlive(t *testing.T) {
+	clock := newFakeClock()
+	store := newStore(StoreConfig{IdleTTL: time.Hour, JanitorInterval: time.Hour}, clock.Now)
+	defer store.Close()
+
+	deck := NewStandardDeck()
+	require.NoError(t, store.Add(&deck))
+
+	clock.Advance(45 * time.Minute)
+	listed, _, err := store.List(DeckFilter{}, Page{})
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+
+	clock.Advance(15 * time.Minute)
+	listed, _, err = store.List(DeckFilter{}, Page{})
+	require.NoError(t, err)
+	assert.Empty(t, listed, "Expired decks are not listed")
+	_, err = store.Get(deck.ID)
+	assert.ErrorIs(t, err, ErrDeckExpired)
 }
 
 func TestStoreConcurrentAccess(t *testing.T) {
@@ -121,3 +571,306 @@ func TestStoreConcurrentAccess(t *testing.T) {
 	}
 	assert.Equal(t, deckCount, finalDeckCount, "Expected %d decks in the store after concurrent operations", deckCount)
 }
+
+func TestStoreConcurrentDraws(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, store DeckRepository) {
+		deck := NewStandardDeck()
+		deck.Shuffle()
+		require.NoError(t, store.Add(&deck))
+
+		// Every card of the deck is drawn.
+		const goroutines = 26
+		const drawsEach = 2
+		drawn := make(chan card.Card, goroutines*drawsEach)
+
+		var wg sync.WaitGroup
+		wg.Add(goroutines)
+		for i := 0; i < goroutines; i++ {
+			go func() {
+				defer wg.Done()
+				for j := 0; j < drawsEach; j++ {
+					err := store.Update(deck.ID, func(d *Deck) error {
+						cards, err := d.Draw(1)
+						if err != nil {
+							return err
+						}
+						drawn <- cards[0]
+						return nil
+					})
+					assert.NoError(t, err)
+
+					// Reads happen while other goroutines draw.
+					_, err = store.Get(deck.ID)
+					assert.NoError(t, err)
+				}
+			}()
+		}
+		wg.Wait()
+		close(drawn)
+
+		dealt := make(map[card.Card]bool)
+		for c := range drawn {
+			assert.False(t, dealt[c], "card %s was dealt twice", c)
+			dealt[c] = true
+		}
+		assert.Len(t, dealt, 52)
+
+		retrievedDeck, err := store.Get(deck.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, retrievedDeck.Remaining)
+		assert.Empty(t, retrievedDeck.Cards)
+		assert.Len(t, retrievedDeck.Drawn, 52)
+		assert.Equal(t, uint64(goroutines*drawsEach), retrievedDeck.Version, "No update is lost")
+	})
+}
+
+// fakeClock is a clock for Stores in tests, which only moves forward when told to.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2023, time.May, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestStoreIdleTTL(t *testing.T) {
+	clock := newFakeClock()
+	store := newStore(StoreConfig{IdleTTL: time.Hour, JanitorInterval: time.Hour}, clock.Now)
+	defer store.Close()
+
+	deck := NewStandardDeck()
+	require.NoError(t, store.Add(&deck))
+	assert.Equal(t, clock.Now().Add(time.Hour), deck.ExpiresAt)
+
+	// Accessing the deck keeps it alive.
+	clock.Advance(50 * time.Minute)
+	retrievedDeck, err := store.Get(deck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Now().Add(time.Hour), retrievedDeck.ExpiresAt)
+
+	clock.Advance(50 * time.Minute)
+	err = store.Update(deck.ID, func(d *Deck) error {
+		assert.Equal(t, clock.Now().Add(time.Hour), d.ExpiresAt)
+		return nil
+	})
+	require.NoError(t, err)
+
+	clock.Advance(time.Hour)
+	_, err = store.Get(deck.ID)
+	assert.ErrorIs(t, err, ErrDeckExpired)
+	err = store.Update(deck.ID, func(d *Deck) error { return nil })
+	assert.ErrorIs(t, err, ErrDeckExpired)
+	err = store.Remove(deck.ID)
+	assert.ErrorIs(t, err, ErrDeckExpired)
+}
+
+func TestStoreMaxAge(t *testing.T) {
+	clock := newFakeClock()
+	store := newStore(StoreConfig{IdleTTL: time.Hour, MaxAge: 90 * time.Minute, JanitorInterval: time.Hour}, clock.Now)
+	defer store.Close()
+
+	deck := NewStandardDeck()
+	addedAt := clock.Now()
+	require.NoError(t, store.Add(&deck))
+
+	clock.Advance(time.Hour - time.Minute)
+	retrievedDeck, err := store.Get(deck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, addedAt.Add(90*time.Minute), retrievedDeck.ExpiresAt, "The deck expires at its max age")
+
+	// The deck expires even though it is still being used.
+	clock.Advance(31 * time.Minute)
+	_, err = store.Get(deck.ID)
+	assert.ErrorIs(t, err, ErrDeckExpired)
+}
+
+func TestStoreWithoutExpiration(t *testing.T) {
+	clock := newFakeClock()
+	store := newStore(StoreConfig{}, clock.Now)
+	defer store.Close()
+
+	deck := NewStandardDeck()
+	require.NoError(t, store.Add(&deck))
+	assert.True(t, deck.ExpiresAt.IsZero())
+
+	clock.Advance(365 * 24 * time.Hour)
+	store.evictExpired()
+	_, err := store.Get(deck.ID)
+	assert.NoError(t, err)
+}
+
+func TestStoreEvictExpired(t *testing.T) {
+	clock := newFakeClock()
+	store := newStore(StoreConfig{IdleTTL: time.Hour, ExpiredRetention: 24 * time.Hour, JanitorInterval: time.Hour}, clock.Now)
+	defer store.Close()
+
+	idle := NewStandardDeck()
+	used := NewStandardDeck()
+	require.NoError(t, store.Add(&idle))
+	require.NoError(t, store.Add(&used))
+
+	clock.Advance(45 * time.Minute)
+	_, err := store.Get(used.ID)
+	require.NoError(t, err)
+
+	clock.Advance(45 * time.Minute)
+	store.evictExpired()
+	assert.NotContains(t, store.decks, idle.ID, "The idle deck is evicted")
+	assert.Contains(t, store.decks, used.ID)
+
+	_, err = store.Get(idle.ID)
+	assert.ErrorIs(t, err, ErrDeckExpired)
+
+	// Expired decks are eventually forgotten.
+	clock.Advance(24 * time.Hour)
+	store.evictExpired()
+	_, err = store.Get(idle.ID)
+	assert.ErrorIs(t, err, ErrDeckNotFound)
+}
+
+func TestStoreJanitor(t *testing.T) {
+	clock := newFakeClock()
+	store := newStore(StoreConfig{IdleTTL: time.Hour, JanitorInterval: time.Millisecond}, clock.Now)
+	defer store.Close()
+
+	deck := NewStandardDeck()
+	require.NoError(t, store.Add(&deck))
+
+	clock.Advance(time.Hour)
+	assert.Eventually(t, func() bool {
+		store.mu.RLock()
+		defer store.mu.RUnlock()
+		_, exists := store.decks[deck.ID]
+		return !exists
+	}, time.Second, time.Millisecond, "The janitor evicts the expired deck in the background")
+}
+
+func TestStoreRejectsDecksWhenFull(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config StoreConfig
+	}{
+		{
+			name:   "too many decks",
+			config: StoreConfig{MaxDecks: 2},
+		},
+		{
+			name:   "too many cards",
+			config: StoreConfig{MaxTotalCards: 120},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewStoreWithConfig(tc.config)
+			defer store.Close()
+
+			first, second, third := NewStandardDeck(), NewStandardDeck(), NewStandardDeck()
+			require.NoError(t, store.Add(&first))
+			require.NoError(t, store.Add(&second))
+
+			err := store.Add(&third)
+			assert.ErrorIs(t, err, ErrStoreFull)
+			_, err = store.Get(third.ID)
+			assert.ErrorIs(t, err, ErrDeckNotFound)
+
+			// Removing a deck makes room for another one.
+			require.NoError(t, store.Remove(first.ID))
+			assert.NoError(t, store.Add(&third))
+
+			assert.Equal(t, StoreStats{Decks: 2, Cards: 104, Rejected: 1}, store.Stats())
+		})
+	}
+}
+
+func TestStoreRejectsDecksLargerThanCapacity(t *testing.T) {
+	store := NewStoreWithConfig(StoreConfig{MaxTotalCards: 100, WhenFull: EvictLeastRecentlyUsed})
+	defer store.Close()
+
+	small, err := NewPartialDeck([]string{"AS", "KD"})
+	require.NoError(t, err)
+	require.NoError(t, store.Add(&small))
+
+	shoe, err := NewShoe(2)
+	require.NoError(t, err)
+	assert.ErrorIs(t, store.Add(&shoe), ErrStoreFull)
+
+	// No deck is evicted for a deck which would never fit.
+	_, err = store.Get(small.ID)
+	assert.NoError(t, err)
+}
+
+func TestStoreEvictsLeastRecentlyUsedDecks(t *testing.T) {
+	store := NewStoreWithConfig(StoreConfig{MaxDecks: 3, WhenFull: EvictLeastRecentlyUsed})
+	defer store.Close()
+
+	decks := []Deck{NewStandardDeck(), NewStandardDeck(), NewStandardDeck()}
+	for i := range decks {
+		require.NoError(t, store.Add(&decks[i]))
+	}
+
+	// The first deck is used, so the second one is now the least recently used.
+	err := store.Update(decks[0].ID, func(d *Deck) error {
+		_, err := d.Draw(1)
+		return err
+	})
+	require.NoError(t, err)
+
+	newDeck := NewStandardDeck()
+	require.NoError(t, store.Add(&newDeck))
+
+	_, err = store.Get(decks[1].ID)
+	assert.ErrorIs(t, err, ErrDeckNotFound, "The least recently used deck is evicted")
+	for _, id := range []uuid.UUID{decks[0].ID, decks[2].ID, newDeck.ID} {
+		_, err = store.Get(id)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, StoreStats{Decks: 3, Cards: 156, Evicted: 1}, store.Stats())
+}
+
+func TestStoreEvictsDecksUntilTheDeckFits(t *testing.T) {
+	store := NewStoreWithConfig(StoreConfig{MaxTotalCards: 110, WhenFull: EvictLeastRecentlyUsed})
+	defer store.Close()
+
+	first, second := NewStandardDeck(), NewStandardDeck()
+	require.NoError(t, store.Add(&first))
+	require.NoError(t, store.Add(&second))
+
+	shoe, err := NewShoe(2)
+	require.NoError(t, err)
+	require.NoError(t, store.Add(&shoe))
+
+	assert.Equal(t, StoreStats{Decks: 1, Cards: 104, Evicted: 2}, store.Stats())
+}
+
+func TestStoreStatsCountExpiredDecks(t *testing.T) {
+	clock := newFakeClock()
+	store := newStore(StoreConfig{IdleTTL: time.Hour, JanitorInterval: time.Hour}, clock.Now)
+	defer store.Close()
+
+	first, second := NewStandardDeck(), NewStandardDeck()
+	require.NoError(t, store.Add(&first))
+	require.NoError(t, store.Add(&second))
+
+	clock.Advance(time.Hour)
+	// One deck expires on access, the other one is evicted by the janitor.
+	_, err := store.Get(first.ID)
+	require.ErrorIs(t, err, ErrDeckExpired)
+	store.evictExpired()
+
+	assert.Equal(t, StoreStats{Expired: 2}, store.Stats())
+}
@@ -0,0 +1,162 @@
+package deck
+
+import (
+	"fmt"
+)
+
+// Undo reverts the last n operations on the Deck, from the most recent one, as if they never happened: the cards
+// (remaining, drawn, and in piles), and whether the Deck is shuffled and closed, are restored exactly as they were
+// before. An operation is an update of the Deck in a DeckRepository (see Event.Version), such as a draw or a shuffle.
+// The creation of the Deck can not be undone, nor can the operations compacted out of its History (see
+// EventCompacted).
+// Undoing does not remove anything from the History: it is recorded as an event too, and can be reverted with Redo.
+// Note that the Shuffler of the Deck is not reverted: shuffling again after undoing a shuffle (e.g. with a seed) does
+// not give the same order.
+// An operation which let the order of the Deck be revealed (see revealing) can not be undone either: the Deck would be
+// played again with cards everyone may know.
+// It returns the versions of the undone operations, ErrNothingToUndo if the Deck has fewer than n operations to undo,
+// or ErrOrderRevealed if one of them let the order of the Deck be revealed. In that case, nothing is undone.
+func (d *Deck) Undo(n int) ([]uint64, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("number of operations to undo should be positive")
+	}
+
+	ops, err := d.operations()
+	if err != nil {
+		return nil, err
+	}
+	if n > len(ops.done) {
+		return nil, fmt.Errorf("%w: only %d operations can be undone", ErrNothingToUndo, len(ops.done))
+	}
+
+	revealing, err := d.revealing(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]uint64, n)
+	for i := range versions {
+		versions[i] = ops.done[len(ops.done)-1-i]
+		if revealing[versions[i]] {
+			return nil, fmt.Errorf("%w: operation %d can not be undone", ErrOrderRevealed, versions[i])
+		}
+	}
+	return versions, d.rewind(Event{Type: EventUndone, Versions: versions})
+}
+
+// Redo replays the last n operations undone with Undo, from the most recently undone one. Operations can only be
+// redone until a new operation happens on the Deck.
+// It returns the versions of the redone operations, or ErrNothingToRedo if fewer than n operations can be redone. In
+// that case, nothing is redone.
+func (d *Deck) Redo(n int) ([]uint64, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("number of operations to redo should be positive")
+	}
+
+	ops, err := d.operations()
+	if err != nil {
+		return nil, err
+	}
+	if n > len(ops.undone) {
+		return nil, fmt.Errorf("%w: only %d operations can be redone", ErrNothingToRedo, len(ops.undone))
+	}
+
+	versions := make([]uint64, n)
+	for i := range versions {
+		versions[i] = ops.undone[len(ops.undone)-1-i]
+	}
+	return versions, d.rewind(Event{Type: EventRedone, Versions: versions})
+}
+
+// rewind records the undo (or redo) event, and rebuilds the cards of the Deck from its History. If the History can not
+// be replayed, the event is not recorded.
+func (d *Deck) rewind(event Event) error {
+	d.record(event)
+	replayed, err := d.Replay()
+	if err != nil {
+		d.History = d.History[:len(d.History)-1]
+		return err
+	}
+
+	d.Cards = replayed.Cards
+	d.Remaining = replayed.Remaining
+	d.Drawn = replayed.Drawn
+	d.Piles = replayed.Piles
+	d.Shuffled = replayed.Shuffled
+	d.Closed = replayed.Closed
+	return nil
+}
+
+// revealing returns the operations which let the order of the Deck be revealed: they closed the Deck, or exhausted it
+// after it was shuffled with a commitment, so its Proof (and any order it was in before) could be revealed (see
+// RevealProof). The Proof of the Deck may have been discarded since, but the order may already be known.
+func (d *Deck) revealing(ops operations) (map[uint64]bool, error) {
+	revealing := make(map[uint64]bool)
+	committed, revealable := false, false
+	_, err := d.replay(d.History, ops.reverted, func(event Event, replayed *Deck) {
+		committed = committed || event.committed()
+		wasRevealable := revealable
+		revealable = replayed.Closed || (committed && replayed.Remaining == 0)
+		if revealable && !wasRevealable {
+			revealing[event.Version] = true
+		}
+	})
+	return revealing, err
+}
+
+// operations holds the state of the operations on a Deck, as recorded in its History, by version (see Event.Version).
+type operations struct {
+	// done holds the operations which can be undone, from the oldest to the most recent.
+	done []uint64
+	// undone holds the operations which can be redone, from the first undone to the most recently undone.
+	undone []uint64
+	// reverted holds the operations which are undone, including those which can not be redone anymore.
+	reverted map[uint64]bool
+}
+
+// operations returns the state of the operations on the Deck. It returns ErrInvalidHistory if the History undoes (or
+// redoes) operations which can not be undone (or redone).
+func (d *Deck) operations() (operations, error) {
+	ops := operations{reverted: make(map[uint64]bool)}
+	// The operations up to compacted are part of the state of the Deck recorded by EventCompacted.
+	var compacted uint64
+	for _, event := range d.History {
+		switch event.Type {
+		case EventCompacted:
+			compacted = event.Version
+		case EventUndone:
+			for _, version := range event.Versions {
+				if version <= compacted {
+					continue
+				}
+				if len(ops.done) == 0 || ops.done[len(ops.done)-1] != version {
+					return ops, fmt.Errorf("%w: event %d: operation %d can not be undone", ErrInvalidHistory, event.Sequence, version)
+				}
+				ops.done = ops.done[:len(ops.done)-1]
+				ops.undone = append(ops.undone, version)
+				ops.reverted[version] = true
+			}
+		case EventRedone:
+			for _, version := range event.Versions {
+				if version <= compacted {
+					continue
+				}
+				if len(ops.undone) == 0 || ops.undone[len(ops.undone)-1] != version {
+					return ops, fmt.Errorf("%w: event %d: operation %d can not be redone", ErrInvalidHistory, event.Sequence, version)
+				}
+				ops.undone = ops.undone[:len(ops.undone)-1]
+				ops.done = append(ops.done, version)
+				delete(ops.reverted, version)
+			}
+		default:
+			// The events recorded before the Deck was added to a repository belong to its creation.
+			if event.Version == 0 || (len(ops.done) > 0 && ops.done[len(ops.done)-1] == event.Version) {
+				continue
+			}
+			// A new operation: the undone operations can not be redone anymore.
+			ops.done = append(ops.done, event.Version)
+			ops.undone = nil
+		}
+	}
+	return ops, nil
+}
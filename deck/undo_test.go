@@ -0,0 +1,262 @@
+package deck
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// newStoredDeck adds a new shuffled deck to a new Store, and returns both.
+func newStoredDeck(t *testing.T) (*Store, *Deck) {
+	store := NewStore()
+	deck := NewStandardDeck()
+	deck.SetShuffler(NewSeededShuffler(42))
+	deck.Shuffle()
+	require.NoError(t, store.Add(&deck))
+	return store, &deck
+}
+
+// update applies fn to the deck in the store, and returns the updated deck.
+func update(t *testing.T, store *Store, d *Deck, fn func(d *Deck) error) *Deck {
+	var updated *Deck
+	err := store.Update(d.ID, func(d *Deck) error {
+		updated = d
+		return fn(d)
+	})
+	require.NoError(t, err)
+	return updated
+}
+
+func TestUndo(t *testing.T) {
+	store, deck := newStoredDeck(t)
+	created := deck.Clone()
+
+	drawn := update(t, store, deck, func(d *Deck) error {
+		_, err := d.DrawToPile("alice", 5)
+		return err
+	})
+	afterDraw := drawn.Clone()
+
+	update(t, store, deck, func(d *Deck) error {
+		d.ReturnAll()
+		d.RiffleShuffle()
+		return nil
+	})
+
+	// Undoing the shuffle restores the deck as it was after the draw, with its pile.
+	undone := update(t, store, deck, func(d *Deck) error {
+		versions, err := d.Undo(1)
+		assert.Equal(t, []uint64{2}, versions)
+		return err
+	})
+	assert.Equal(t, afterDraw.Cards, undone.Cards)
+	assert.Equal(t, afterDraw.Remaining, undone.Remaining)
+	assert.Equal(t, afterDraw.Piles, undone.Piles)
+
+	// Undoing the draw restores the deck as it was created.
+	undone = update(t, store, deck, func(d *Deck) error {
+		_, err := d.Undo(1)
+		return err
+	})
+	assert.Equal(t, created.Cards, undone.Cards)
+	assert.Equal(t, created.Remaining, undone.Remaining)
+	assert.True(t, undone.Shuffled, "The creation of the deck is not undone")
+	assert.Empty(t, undone.Piles)
+	assert.NoError(t, undone.VerifyHistory())
+
+	// The creation can not be undone.
+	err := store.Update(deck.ID, func(d *Deck) error {
+		_, err := d.Undo(1)
+		return err
+	})
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	history := undone.History
+	assert.Equal(t, EventUndone, history[len(history)-1].Type, "Undoing is recorded in the history")
+	assert.Equal(t, []uint64{1}, history[len(history)-1].Versions)
+}
+
+func TestUndoSeveralOperations(t *testing.T) {
+	store, deck := newStoredDeck(t)
+	created := deck.Clone()
+
+	for i := 0; i < 3; i++ {
+		update(t, store, deck, func(d *Deck) error {
+			_, err := d.Draw(2)
+			return err
+		})
+	}
+
+	undone := update(t, store, deck, func(d *Deck) error {
+		versions, err := d.Undo(3)
+		assert.Equal(t, []uint64{3, 2, 1}, versions, "The most recent operation is undone first")
+		return err
+	})
+	assert.Equal(t, created.Cards, undone.Cards)
+	assert.Equal(t, 52, undone.Remaining)
+	assert.Empty(t, undone.Drawn)
+}
+
+func TestRedo(t *testing.T) {
+	store, deck := newStoredDeck(t)
+
+	drawn := update(t, store, deck, func(d *Deck) error {
+		_, err := d.Draw(3)
+		return err
+	})
+	afterDraw := drawn.Clone()
+
+	update(t, store, deck, func(d *Deck) error {
+		_, err := d.Undo(1)
+		return err
+	})
+	redone := update(t, store, deck, func(d *Deck) error {
+		versions, err := d.Redo(1)
+		assert.Equal(t, []uint64{1}, versions)
+		return err
+	})
+	assert.Equal(t, afterDraw.Cards, redone.Cards)
+	assert.Equal(t, afterDraw.Drawn, redone.Drawn)
+	assert.NoError(t, redone.VerifyHistory())
+
+	// Once redone, the draw can be undone again.
+	update(t, store, deck, func(d *Deck) error {
+		_, err := d.Undo(1)
+		return err
+	})
+
+	// A new operation can not be followed by a redo.
+	update(t, store, deck, func(d *Deck) error {
+		_, err := d.Draw(1)
+		return err
+	})
+	err := store.Update(deck.ID, func(d *Deck) error {
+		_, err := d.Redo(1)
+		return err
+	})
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+
+	retrieved, err := store.Get(deck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 51, retrieved.Remaining, "Only the new draw is left")
+	assert.NoError(t, retrieved.VerifyHistory())
+}
+
+func TestUndoRevealedOrder(t *testing.T) {
+	testCases := []struct {
+		name      string
+		committed bool
+		reveal    func(d *Deck) error
+	}{
+		{
+			name: "closed deck",
+			reveal: func(d *Deck) error {
+				d.Close()
+				return nil
+			},
+		},
+		{
+			name:      "exhausted deck with a commitment",
+			committed: true,
+			reveal: func(d *Deck) error {
+				_, err := d.Draw(d.Remaining)
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewStore()
+			deck := NewStandardDeck()
+			if tc.committed {
+				require.NoError(t, deck.CommitShuffle(""))
+			}
+			require.NoError(t, store.Add(&deck))
+			update(t, store, &deck, tc.reveal)
+
+			// The proof can be revealed, even once the deck is reordered and the proof discarded.
+			update(t, store, &deck, func(d *Deck) error {
+				d.ReturnAll()
+				d.Shuffle()
+				return nil
+			})
+			update(t, store, &deck, func(d *Deck) error {
+				_, err := d.Undo(1)
+				return err
+			})
+
+			err := store.Update(deck.ID, func(d *Deck) error {
+				_, err := d.Undo(1)
+				return err
+			})
+			assert.ErrorIs(t, err, ErrOrderRevealed, "The deck can not be played again with cards everyone may know")
+		})
+	}
+
+	// Without a commitment, there is no proof to reveal: an exhausted deck is only a misclick away from being played.
+	store, deck := newStoredDeck(t)
+	update(t, store, deck, func(d *Deck) error {
+		_, err := d.Draw(d.Remaining)
+		return err
+	})
+	undone := update(t, store, deck, func(d *Deck) error {
+		_, err := d.Undo(1)
+		return err
+	})
+	assert.Equal(t, 52, undone.Remaining)
+}
+
+func TestUndoInvalid(t *testing.T) {
+	store, deck := newStoredDeck(t)
+	update(t, store, deck, func(d *Deck) error {
+		_, err := d.Draw(1)
+		return err
+	})
+
+	testCases := []struct {
+		name        string
+		operation   func(d *Deck) error
+		expectedErr error
+	}{
+		{
+			name: "more operations than the deck went through",
+			operation: func(d *Deck) error {
+				_, err := d.Undo(2)
+				return err
+			},
+			expectedErr: ErrNothingToUndo,
+		},
+		{
+			name: "redo without undo",
+			operation: func(d *Deck) error {
+				_, err := d.Redo(1)
+				return err
+			},
+			expectedErr: ErrNothingToRedo,
+		},
+		{
+			name: "no operations",
+			operation: func(d *Deck) error {
+				_, err := d.Undo(0)
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			retrieved, err := store.Get(deck.ID)
+			require.NoError(t, err)
+			historyLength := len(retrieved.History)
+
+			err = tc.operation(retrieved)
+			require.Error(t, err)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			}
+			assert.Len(t, retrieved.History, historyLength, "Nothing is recorded when nothing is undone")
+			assert.Equal(t, 51, retrieved.Remaining)
+		})
+	}
+}
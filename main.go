@@ -8,17 +8,69 @@
 // - GET /decks/:deck_id/draw: Draw a specified number of cards from an existing deck
 //
 // The API is served on port 8080 by default.
+//
+// Decks are kept in memory by default. Start with `-store bolt -db <path>` to keep them in a BoltDB file instead, so
+// they survive a restart. Decks kept in memory expire after `-idle-ttl` without being used, or `-max-age` after being
+// created. Their number is limited by `-max-decks` and `-max-cards`: when full, new decks are rejected, or the least
+// recently used decks are evicted with `-when-full evict`.
+//
+// Admin endpoints, such as DELETE /decks, are only enabled with `-admin-token <token>`.
 package main
 
 import (
 	"deck-of-cards/api"
+	"deck-of-cards/deck"
+	"flag"
 	"fmt"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	storeKind := flag.String("store", "memory", "where to keep the decks: memory or bolt")
+	dbPath := flag.String("db", "decks.db", "path of the BoltDB file, when -store=bolt")
+	idleTTL := flag.Duration("idle-ttl", 0, "how long a deck is kept without being used, when -store=memory (0 keeps decks forever)")
+	maxAge := flag.Duration("max-age", 0, "how long a deck is kept after being created, when -store=memory (0 keeps decks forever)")
+	maxDecks := flag.Int("max-decks", 0, "maximum number of decks, when -store=memory (0 is unlimited)")
+	maxCards := flag.Int("max-cards", 0, "maximum number of cards of all the decks, when -store=memory (0 is unlimited)")
+	whenFull := flag.String("when-full", "reject", "what to do with new decks when the store is full: reject or evict (the least recently used decks)")
+	adminToken := flag.String("admin-token", "", "token of the administrator, enabling the admin endpoints (e.g. DELETE /decks)")
+	flag.Parse()
+
+	var opts []api.Option
+	switch *storeKind {
+	case "memory":
+		config := deck.StoreConfig{IdleTTL: *idleTTL, MaxAge: *maxAge, MaxDecks: *maxDecks, MaxTotalCards: *maxCards}
+		switch *whenFull {
+		case "reject":
+			config.WhenFull = deck.RejectWhenFull
+		case "evict":
+			config.WhenFull = deck.EvictLeastRecentlyUsed
+		default:
+			fmt.Printf("Unknown -when-full %q. Use reject or evict.\n", *whenFull)
+			return
+		}
+		store := deck.NewStoreWithConfig(config)
+		defer store.Close()
+		opts = append(opts, api.WithRepository(store))
+	case "bolt":
+		store, err := deck.NewBoltStore(*dbPath)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer store.Close()
+		opts = append(opts, api.WithRepository(store))
+	default:
+		fmt.Printf("Unknown store %q. Use memory or bolt.\n", *storeKind)
+		return
+	}
+
+	if *adminToken != "" {
+		opts = append(opts, api.WithAdminToken(*adminToken))
+	}
+
 	gin.SetMode(gin.ReleaseMode)
-	server := api.NewServer()
+	server := api.NewServer(opts...)
 
 	// TODO: Get port to run from flag/env variable
 	err := server.Run(":8080")